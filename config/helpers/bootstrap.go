@@ -125,6 +125,12 @@ func CreateResourceFromFS(ctx context.Context, client dynamic.Interface, mapper
 		return fmt.Errorf("could not read %s: %w", filename, err)
 	}
 
+	return CreateResourcesFromYAML(ctx, client, mapper, batteriesIncluded, filename, raw, transformers...)
+}
+
+// CreateResourcesFromYAML creates the resources of the given, possibly multi-document YAML.
+// The name is used to identify the YAML in errors.
+func CreateResourcesFromYAML(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, batteriesIncluded sets.String, name string, raw []byte, transformers ...TransformFileFunc) error {
 	if len(raw) == 0 {
 		return nil // ignore empty files
 	}
@@ -150,7 +156,7 @@ func CreateResourceFromFS(ctx context.Context, client dynamic.Interface, mapper
 		}
 
 		if err := createResourceFromFS(ctx, client, mapper, doc, batteriesIncluded); err != nil {
-			errs = append(errs, fmt.Errorf("failed to create resource %s doc %d: %w", filename, i, err))
+			errs = append(errs, fmt.Errorf("failed to create resource %s doc %d: %w", name, i, err))
 		}
 	}
 	return apimachineryerrors.NewAggregate(errs)
@@ -156,8 +156,28 @@ func ValidateAPIResourceSchemaSpec(ctx context.Context, spec *apisv1alpha1.APIRe
 		allErrs = append(allErrs, crdvalidation.ValidateCustomResourceDefinitionNames(&crdNames, fldPath.Child("names"))...)
 	}
 
+	if spec.Conversion != nil {
+		allErrs = append(allErrs, ValidateAPIResourceSchemaConversion(spec.Conversion, fldPath.Child("conversion"))...)
+	}
+
 	// TODO(sttts): validate predecessors
-	// TODO(sttts): validate conversions
+
+	return allErrs
+}
+
+// ValidateAPIResourceSchemaConversion validates the conversion settings of an APIResourceSchema. Only the
+// None strategy is allowed. Conversion webhooks would be called by every shard the schema is bound on, with
+// the objects of the consumers, hence they are not supported until they can be routed through the APIExport
+// virtual workspace.
+func ValidateAPIResourceSchemaConversion(conversion *apiextensionsv1.CustomResourceConversion, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if conversion.Strategy != apiextensionsv1.NoneConverter {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("strategy"), conversion.Strategy, []string{string(apiextensionsv1.NoneConverter)}))
+	}
+	if conversion.Webhook != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("webhook"), "conversion webhooks are not supported"))
+	}
 
 	return allErrs
 }
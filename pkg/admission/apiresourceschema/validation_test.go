@@ -19,6 +19,12 @@ package apiresourceschema
 import (
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 )
 
 func TestValidationOptionDrift(t *testing.T) {
@@ -45,3 +51,61 @@ func TestValidationOptionDrift(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateAPIResourceSchemaConversion(t *testing.T) {
+	webhook := func(clientConfig *apiextensionsv1.WebhookClientConfig, reviewVersions ...string) *apiextensionsv1.CustomResourceConversion {
+		return &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook:  &apiextensionsv1.WebhookConversion{ClientConfig: clientConfig, ConversionReviewVersions: reviewVersions},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		conversion *apiextensionsv1.CustomResourceConversion
+		wantErrs   []string
+	}{
+		{name: "none", conversion: &apiextensionsv1.CustomResourceConversion{Strategy: apiextensionsv1.NoneConverter}},
+		{
+			name:       "unknown strategy",
+			conversion: &apiextensionsv1.CustomResourceConversion{Strategy: "CEL"},
+			wantErrs:   []string{`conversion.strategy: Unsupported value: "CEL": supported values: "None"`},
+		},
+		{
+			name:       "webhook with url",
+			conversion: webhook(&apiextensionsv1.WebhookClientConfig{URL: pointer.String("https://provider.example.com/convert")}, "v1"),
+			wantErrs: []string{
+				`conversion.strategy: Unsupported value: "Webhook": supported values: "None"`,
+				"conversion.webhook: Forbidden: conversion webhooks are not supported",
+			},
+		},
+		{
+			name: "webhook with service",
+			conversion: webhook(&apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{Namespace: "default", Name: "converter"},
+			}, "v1"),
+			wantErrs: []string{
+				`conversion.strategy: Unsupported value: "Webhook": supported values: "None"`,
+				"conversion.webhook: Forbidden: conversion webhooks are not supported",
+			},
+		},
+		{
+			name: "none with webhook url",
+			conversion: &apiextensionsv1.CustomResourceConversion{
+				Strategy: apiextensionsv1.NoneConverter,
+				Webhook:  &apiextensionsv1.WebhookConversion{ClientConfig: &apiextensionsv1.WebhookClientConfig{URL: pointer.String("https://169.254.169.254/")}},
+			},
+			wantErrs: []string{"conversion.webhook: Forbidden: conversion webhooks are not supported"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateAPIResourceSchemaConversion(tt.conversion, field.NewPath("conversion"))
+			var got []string
+			for _, err := range errs {
+				got = append(got, err.Error())
+			}
+			require.Equal(t, tt.wantErrs, got)
+		})
+	}
+}
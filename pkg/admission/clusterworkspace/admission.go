@@ -23,14 +23,22 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/kcp-dev/logicalcluster/v2"
+
 	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/admission/initializer"
 	kuser "k8s.io/apiserver/pkg/authentication/user"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	kubernetesinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/clusters"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 )
@@ -59,17 +67,22 @@ func Register(plugins *admission.Plugins) {
 
 type clusterWorkspace struct {
 	*admission.Handler
+
+	getConfigMap func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error)
 }
 
 // Ensure that the required admission interfaces are implemented.
 var _ admission.MutationInterface = &clusterWorkspace{}
 var _ admission.ValidationInterface = &clusterWorkspace{}
+var _ admission.InitializationValidator = &clusterWorkspace{}
+var _ = initializer.WantsExternalKubeInformerFactory(&clusterWorkspace{})
 
 var phaseOrdinal = map[tenancyv1alpha1.ClusterWorkspacePhaseType]int{
 	tenancyv1alpha1.ClusterWorkspacePhaseType(""):     1,
 	tenancyv1alpha1.ClusterWorkspacePhaseScheduling:   2,
 	tenancyv1alpha1.ClusterWorkspacePhaseInitializing: 3,
 	tenancyv1alpha1.ClusterWorkspacePhaseReady:        4,
+	tenancyv1alpha1.ClusterWorkspacePhaseTrashed:      5,
 }
 
 // Admit ensures that
@@ -108,7 +121,9 @@ func (o *clusterWorkspace) Admit(ctx context.Context, a admission.Attributes, _
 // - the workspace only does a valid phase transition
 // - has a valid type
 // - has valid initializers when transitioning to initializing
+// - is only trashed when being deleted
 // - the user is recorded in annotations on create
+// - its name is not reserved for the restore of a trashed workspace of another owner
 func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
 	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("clusterworkspaces") {
 		return nil
@@ -166,12 +181,20 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 				return admission.NewForbidden(a, fmt.Errorf("expected user annotation %s=%s", tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey, userInfo))
 			}
 		}
+
+		if err := o.validateRestoreReservation(ctx, a, cw); err != nil {
+			return err
+		}
 	}
 
 	if phaseOrdinal[cw.Status.Phase] > phaseOrdinal[tenancyv1alpha1.ClusterWorkspacePhaseInitializing] && len(cw.Status.Initializers) > 0 {
 		return admission.NewForbidden(a, fmt.Errorf("spec.initializers must be empty for phase %s", cw.Status.Phase))
 	}
 
+	if cw.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseTrashed && cw.DeletionTimestamp == nil {
+		return admission.NewForbidden(a, fmt.Errorf("phase %s is only valid for deleted workspaces", cw.Status.Phase))
+	}
+
 	if phaseOrdinal[cw.Status.Phase] > phaseOrdinal[tenancyv1alpha1.ClusterWorkspacePhaseScheduling] {
 		if cw.Status.Location.Current == "" {
 			return admission.NewForbidden(a, fmt.Errorf("status.location.current must be set for phase %s", cw.Status.Phase))
@@ -184,6 +207,77 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 	return nil
 }
 
+// validateRestoreReservation rejects the creation of a workspace whose name is reserved for the restore of
+// a trashed workspace, unless it is created for the owner of the trashed workspace. Otherwise, the new
+// workspace would take over the content retained for the trashed one.
+func (o *clusterWorkspace) validateRestoreReservation(ctx context.Context, a admission.Attributes, cw *tenancyv1alpha1.ClusterWorkspace) error {
+	if cw.Name == "" {
+		// generated names are never reserved
+		return nil
+	}
+
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	if !o.WaitForReady() {
+		return admission.NewForbidden(a, fmt.Errorf("not yet ready to handle request"))
+	}
+
+	reservation, err := o.getConfigMap(RestoreReservationCluster, RestoreReservationNamespace, RestoreReservationName(clusterName, cw.Name))
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return admission.NewForbidden(a, err)
+	}
+	if !IsRestoreReservationFor(reservation, clusterName, cw.Name) {
+		return admission.NewForbidden(a, fmt.Errorf("invalid restore reservation %s for workspace %q", reservation.Name, cw.Name))
+	}
+
+	reservedFor, err := ownerUsername(reservation.Data[RestoreReservationOwnerKey])
+	if err != nil {
+		return admission.NewForbidden(a, err)
+	}
+	owner, err := ownerUsername(cw.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey])
+	if err != nil {
+		return admission.NewForbidden(a, err)
+	}
+	if owner != reservedFor {
+		return admission.NewForbidden(a, fmt.Errorf("name %q is reserved for the restore of a trashed workspace of another owner", cw.Name))
+	}
+
+	return nil
+}
+
+// ownerUsername returns the username recorded in the given ExperimentalClusterWorkspaceOwnerAnnotationKey value.
+func ownerUsername(annotation string) (string, error) {
+	if annotation == "" {
+		return "", nil
+	}
+	var info authenticationv1.UserInfo
+	if err := json.Unmarshal([]byte(annotation), &info); err != nil {
+		return "", fmt.Errorf("invalid %s annotation: %w", tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey, err)
+	}
+	return info.Username, nil
+}
+
+func (o *clusterWorkspace) ValidateInitialization() error {
+	if o.getConfigMap == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ConfigMap lister")
+	}
+	return nil
+}
+
+// SetExternalKubeInformerFactory implements the WantsExternalKubeInformerFactory interface.
+func (o *clusterWorkspace) SetExternalKubeInformerFactory(f kubernetesinformers.SharedInformerFactory) {
+	configMapsInformer := f.Core().V1().ConfigMaps()
+	o.SetReadyFunc(configMapsInformer.Informer().HasSynced)
+	o.getConfigMap = func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+		return configMapsInformer.Lister().ConfigMaps(namespace).Get(clusters.ToClusterAwareKey(clusterName, name))
+	}
+}
+
 // updateUnstructured updates the given unstructured object to match the given cluster workspace.
 func updateUnstructured(u *unstructured.Unstructured, cw *tenancyv1alpha1.ClusterWorkspace) error {
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cw)
@@ -18,12 +18,15 @@ package clusterworkspace
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -218,6 +221,7 @@ func TestAdmit(t *testing.T) {
 }
 
 func TestValidate(t *testing.T) {
+	now := metav1.Now()
 	tests := []struct {
 		name           string
 		a              admission.Attributes
@@ -532,6 +536,120 @@ func TestValidate(t *testing.T) {
 				}),
 			expectedErrors: []string{"cannot transition from \"Ready\" to \"Initializing\""},
 		},
+		{
+			name: "allows transition from Ready to Trashed when deleted",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					Annotations:       map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					DeletionTimestamp: &now,
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+						Name: "foo",
+						Path: "root:org",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			}, &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					Annotations:       map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					DeletionTimestamp: &now,
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+						Name: "foo",
+						Path: "root:org",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseReady,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			}),
+		},
+		{
+			name: "rejects transition from Ready to Trashed when not deleted",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+						Name: "foo",
+						Path: "root:org",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			}, &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Annotations: map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+						Name: "foo",
+						Path: "root:org",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseReady,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			}),
+			expectedErrors: []string{"phase Trashed is only valid for deleted workspaces"},
+		},
+		{
+			name: "rejects transition from Trashed to Ready",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					Annotations:       map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					DeletionTimestamp: &now,
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+						Name: "foo",
+						Path: "root:org",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseReady,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			}, &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					Annotations:       map[string]string{"experimental.tenancy.kcp.dev/owner": "{}"},
+					DeletionTimestamp: &now,
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{
+						Name: "foo",
+						Path: "root:org",
+					},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			}),
+			expectedErrors: []string{"cannot transition from \"Trashed\" to \"Ready\""},
+		},
 		{
 			name: "ignores different resources",
 			a: admission.NewAttributesRecord(
@@ -630,6 +748,9 @@ func TestValidate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			o := &clusterWorkspace{
 				Handler: admission.NewHandler(admission.Create, admission.Update),
+				getConfigMap: func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+					return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+				},
 			}
 			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org")})
 			err := o.Validate(ctx, tt.a, nil)
@@ -647,6 +768,98 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateRestoreReservation(t *testing.T) {
+	owner := func(name string) string {
+		return fmt.Sprintf(`{"username":%q,"uid":"id","groups":["a"]}`, name)
+	}
+	reservation := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: RestoreReservationNamespace,
+			Name:      RestoreReservationName(logicalcluster.New("root:org"), "trashed"),
+		},
+		Data: map[string]string{
+			RestoreReservationClusterKey: "root:org",
+			RestoreReservationNameKey:    "trashed",
+			RestoreReservationOwnerKey:   owner("alice"),
+		},
+	}
+	collision := reservation.DeepCopy()
+	collision.Name = RestoreReservationName(logicalcluster.New("root:org"), "collision")
+	workspace := func(name, owner string) *tenancyv1alpha1.ClusterWorkspace {
+		ws := &tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+				Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{Name: "universal", Path: "root"},
+			},
+		}
+		if owner != "" {
+			ws.Annotations = map[string]string{"experimental.tenancy.kcp.dev/owner": owner}
+		}
+		return ws
+	}
+
+	tests := []struct {
+		name           string
+		a              admission.Attributes
+		expectedErrors []string
+	}{
+		{
+			name: "unreserved name",
+			a:    createAttrWithUser(workspace("other", owner("bob")), &user.DefaultInfo{Name: "bob", UID: "id", Groups: []string{"a"}}),
+		},
+		{
+			name: "reserved name created by the owner",
+			a:    createAttrWithUser(workspace("trashed", owner("alice")), &user.DefaultInfo{Name: "alice", UID: "id", Groups: []string{"a"}}),
+		},
+		{
+			name:           "reserved name created by another user",
+			a:              createAttrWithUser(workspace("trashed", owner("bob")), &user.DefaultInfo{Name: "bob", UID: "id", Groups: []string{"a"}}),
+			expectedErrors: []string{`name "trashed" is reserved for the restore of a trashed workspace of another owner`},
+		},
+		{
+			name: "reserved name restored for the owner by system:masters",
+			a:    createAttrWithUser(workspace("trashed", owner("alice")), &user.DefaultInfo{Name: "system:kcp", Groups: []string{"system:masters"}}),
+		},
+		{
+			name:           "reserved name created without owner by system:masters",
+			a:              createAttrWithUser(workspace("trashed", ""), &user.DefaultInfo{Name: "admin", Groups: []string{"system:masters"}}),
+			expectedErrors: []string{`name "trashed" is reserved for the restore of a trashed workspace of another owner`},
+		},
+		{
+			name:           "reservation recording another workspace",
+			a:              createAttrWithUser(workspace("collision", owner("alice")), &user.DefaultInfo{Name: "alice", UID: "id", Groups: []string{"a"}}),
+			expectedErrors: []string{`invalid restore reservation`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &clusterWorkspace{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+				getConfigMap: func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+					if clusterName == RestoreReservationCluster && namespace == RestoreReservationNamespace {
+						for _, cm := range []*corev1.ConfigMap{reservation, collision} {
+							if cm.Name == name {
+								return cm, nil
+							}
+						}
+					}
+					return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+				},
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org")})
+			err := o.Validate(ctx, tt.a, nil)
+			if len(tt.expectedErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, expected := range tt.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
 type builder struct {
 	*tenancyv1alpha1.ClusterWorkspaceType
 }
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+
+	configshard "github.com/kcp-dev/kcp/config/shard"
+)
+
+const (
+	// RestoreReservationNamespace is the namespace of the ConfigMaps in the system:shard logical cluster
+	// that reserve the names of trashed workspaces while they are restored.
+	RestoreReservationNamespace = "default"
+
+	// RestoreReservationClusterKey holds the logical cluster of the reserved workspace name.
+	RestoreReservationClusterKey = "cluster"
+	// RestoreReservationNameKey holds the reserved workspace name.
+	RestoreReservationNameKey = "name"
+	// RestoreReservationOwnerKey holds the ExperimentalClusterWorkspaceOwnerAnnotationKey value of the
+	// trashed workspace the name is reserved for.
+	RestoreReservationOwnerKey = "owner"
+)
+
+// RestoreReservationCluster is the logical cluster holding the restore reservations. Tenants cannot
+// write to it, so a reservation is always written by the system.
+var RestoreReservationCluster = configshard.SystemShardCluster
+
+// RestoreReservationName returns the name of the ConfigMap reserving the name of the given workspace
+// in the given logical cluster.
+func RestoreReservationName(clusterName logicalcluster.Name, name string) string {
+	return fmt.Sprintf("clusterworkspace-restore-%x", sha256.Sum224([]byte(clusterName.Join(name).String())))
+}
+
+// IsRestoreReservationFor returns whether the given ConfigMap reserves the name of the given workspace
+// in the given logical cluster.
+func IsRestoreReservationFor(reservation *corev1.ConfigMap, clusterName logicalcluster.Name, name string) bool {
+	return reservation.Name == RestoreReservationName(clusterName, name) &&
+		reservation.Data[RestoreReservationClusterKey] == clusterName.String() &&
+		reservation.Data[RestoreReservationNameKey] == name
+}
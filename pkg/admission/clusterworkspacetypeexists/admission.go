@@ -183,6 +183,9 @@ func (o *clusterWorkspaceTypeExists) Admit(ctx context.Context, a admission.Attr
 		if len(alias.Spec.DefaultAPIBindings) > 0 {
 			cw.Status.Initializers = initialization.EnsureInitializerPresent(tenancyv1alpha1.ClusterWorkspaceAPIBindingsInitializer, cw.Status.Initializers)
 		}
+		if alias.Spec.BootstrapResources != nil {
+			cw.Status.Initializers = initialization.EnsureInitializerPresent(tenancyv1alpha1.ClusterWorkspaceBootstrapResourcesInitializer, cw.Status.Initializers)
+		}
 	}
 
 	return updateUnstructured(u, cw)
@@ -181,6 +181,31 @@ func TestAdmit(t *testing.T) {
 				BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
 			}).ClusterWorkspace,
 		},
+		{
+			name: "adds system:bootstrapresources initializer when bootstrap resources are on an extended type",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org:bar").withBootstrapResources().ClusterWorkspaceType,
+				newType("root:org:foo").extending("root:org:bar").ClusterWorkspaceType,
+			},
+			clusterName: logicalcluster.New("root:org:ws"),
+			a: updateAttr(
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				}).ClusterWorkspace,
+				newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{},
+				}).ClusterWorkspace,
+			),
+			expectedObj: newWorkspace("root:org:ws:test").withType("root:org:foo").withStatus(tenancyv1alpha1.ClusterWorkspaceStatus{
+				Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+				Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+				Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.ClusterWorkspaceBootstrapResourcesInitializer},
+				BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+			}).ClusterWorkspace,
+		},
 		{
 			name:        "ignores different resources",
 			clusterName: logicalcluster.New("root:org:ws"),
@@ -961,6 +986,16 @@ func (b builder) withAPIBindings() builder {
 	return b
 }
 
+func (b builder) withBootstrapResources() builder {
+	b.ClusterWorkspaceType.Spec.BootstrapResources = &tenancyv1alpha1.BootstrapResources{
+		ConfigMap: tenancyv1alpha1.ConfigMapReference{
+			Namespace: "default",
+			Name:      "bootstrap",
+		},
+	}
+	return b
+}
+
 type wsBuilder struct {
 	*tenancyv1alpha1.ClusterWorkspace
 }
@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspacetypequota
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clusters"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+)
+
+const (
+	PluginName = "tenancy.kcp.dev/ClusterWorkspaceTypeQuota"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &clusterWorkspaceTypeQuota{
+				Handler: admission.NewHandler(admission.Create),
+			}, nil
+		})
+}
+
+// clusterWorkspaceTypeQuota enforces the quota of the ClusterWorkspaceType of a workspace when
+// objects are created in the workspace. The quota is read from the ClusterWorkspaceType itself,
+// such that nothing in the workspace can loosen it.
+//
+// The objects are counted on every creation of a limited resource. Concurrent creations are not
+// serialized, hence they can exceed the quota by the number of requests racing each other.
+type clusterWorkspaceTypeQuota struct {
+	*admission.Handler
+
+	getClusterWorkspace     func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error)
+	getClusterWorkspaceType func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error)
+
+	// countObjects returns the number of objects of the given resource in the given logical cluster,
+	// counting at most limit objects.
+	countObjects func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, limit int64) (int64, error)
+}
+
+// Ensure that the required admission interfaces are implemented.
+var (
+	_ = admission.ValidationInterface(&clusterWorkspaceTypeQuota{})
+	_ = admission.InitializationValidator(&clusterWorkspaceTypeQuota{})
+	_ = kcpinitializers.WantsKcpInformers(&clusterWorkspaceTypeQuota{})
+	_ = kcpinitializers.WantsDynamicClusterClient(&clusterWorkspaceTypeQuota{})
+)
+
+// Validate rejects the creation of an object if the workspace already holds as many objects of
+// its resource as the quota of the ClusterWorkspaceType of the workspace allows.
+func (o *clusterWorkspaceTypeQuota) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetSubresource() != "" {
+		return nil
+	}
+
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	parent, hasParent := clusterName.Parent()
+	if !hasParent {
+		// the root workspace has no type
+		return nil
+	}
+
+	if !o.WaitForReady() {
+		return admission.NewForbidden(a, fmt.Errorf("not yet ready to handle request"))
+	}
+
+	ws, err := o.getClusterWorkspace(parent, clusterName.Base())
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	cwt, err := o.getClusterWorkspaceType(logicalcluster.New(ws.Spec.Type.Path), tenancyv1alpha1.ObjectName(ws.Spec.Type.Name))
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	gvr := a.GetResource()
+	max, limited := MaxObjects(cwt.Spec.Quota, gvr.GroupResource())
+	if !limited {
+		return nil
+	}
+	if max > 0 {
+		count, err := o.countObjects(ctx, clusterName, gvr, max)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		if count < max {
+			return nil
+		}
+	}
+
+	return admission.NewForbidden(a, fmt.Errorf("exceeded quota of ClusterWorkspaceType %s:%s: at most %d %s allowed", ws.Spec.Type.Path, ws.Spec.Type.Name, max, gvr.GroupResource()))
+}
+
+// MaxObjects returns the maximal number of objects of the given resource in a workspace with
+// the given quota, and whether the resource is limited at all. If several limits apply to the
+// same resource, the smallest one wins.
+func MaxObjects(quota *tenancyv1alpha1.ClusterWorkspaceTypeQuota, gr schema.GroupResource) (int64, bool) {
+	if quota == nil {
+		return 0, false
+	}
+
+	var max int64
+	limited := false
+	limit := func(n int64) {
+		if !limited || n < max {
+			max = n
+		}
+		limited = true
+	}
+
+	if quota.MaxNamespaces != nil && gr == corev1.Resource("namespaces") {
+		limit(*quota.MaxNamespaces)
+	}
+	if quota.MaxChildWorkspaces != nil && gr == tenancyv1alpha1.Resource("clusterworkspaces") {
+		limit(*quota.MaxChildWorkspaces)
+	}
+	for _, o := range quota.MaxObjects {
+		if o.Group == gr.Group && o.Resource == gr.Resource {
+			limit(o.Max)
+		}
+	}
+
+	return max, limited
+}
+
+func (o *clusterWorkspaceTypeQuota) ValidateInitialization() error {
+	if o.getClusterWorkspace == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspace lister")
+	}
+	if o.getClusterWorkspaceType == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspaceType lister")
+	}
+	if o.countObjects == nil {
+		return fmt.Errorf(PluginName + " plugin needs a dynamic cluster client")
+	}
+	return nil
+}
+
+func (o *clusterWorkspaceTypeQuota) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	workspacesReady := informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced
+	typesReady := informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().HasSynced
+	o.SetReadyFunc(func() bool {
+		return workspacesReady() && typesReady()
+	})
+
+	workspaceLister := informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+	o.getClusterWorkspace = func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+		return workspaceLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+	}
+	typeLister := informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Lister()
+	o.getClusterWorkspaceType = func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+		return typeLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+	}
+}
+
+func (o *clusterWorkspaceTypeQuota) SetDynamicClusterClient(dynamicClusterClient dynamic.ClusterInterface) {
+	o.countObjects = func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, limit int64) (int64, error) {
+		list, err := dynamicClusterClient.Cluster(clusterName).Resource(gvr).List(ctx, metav1.ListOptions{Limit: limit})
+		if err != nil {
+			return 0, err
+		}
+		return int64(len(list.Items)), nil
+	}
+}
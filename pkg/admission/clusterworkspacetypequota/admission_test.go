@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspacetypequota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/utils/pointer"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func createAttr(gvr schema.GroupVersionResource, subresource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		nil,
+		nil,
+		schema.GroupVersionKind{},
+		"",
+		"test",
+		gvr,
+		subresource,
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		nil,
+	)
+}
+
+func TestValidate(t *testing.T) {
+	configmaps := corev1.SchemeGroupVersion.WithResource("configmaps")
+	namespaces := corev1.SchemeGroupVersion.WithResource("namespaces")
+	cowboys := schema.GroupVersionResource{Group: "wildwest.dev", Version: "v1alpha1", Resource: "cowboys"}
+
+	typeWithQuota := &tenancyv1alpha1.ClusterWorkspaceType{
+		ObjectMeta: metav1.ObjectMeta{Name: "limited"},
+		Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+			Quota: &tenancyv1alpha1.ClusterWorkspaceTypeQuota{
+				MaxNamespaces: pointer.Int64(0),
+				MaxObjects: []tenancyv1alpha1.ResourceObjectQuota{
+					{Group: "wildwest.dev", Resource: "cowboys", Max: 2},
+				},
+			},
+		},
+	}
+	typeWithoutQuota := &tenancyv1alpha1.ClusterWorkspaceType{
+		ObjectMeta: metav1.ObjectMeta{Name: "unlimited"},
+	}
+	workspace := func(name, typeName string) *tenancyv1alpha1.ClusterWorkspace {
+		return &tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+				Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{Path: "root", Name: tenancyv1alpha1.ClusterWorkspaceTypeName(typeName)},
+			},
+		}
+	}
+	workspaces := map[string]*tenancyv1alpha1.ClusterWorkspace{
+		"limited":   workspace("limited", "limited"),
+		"unlimited": workspace("unlimited", "unlimited"),
+		"untyped":   workspace("untyped", "missing"),
+	}
+
+	tests := []struct {
+		name          string
+		clusterName   string
+		a             admission.Attributes
+		count         int64
+		wantCounted   bool
+		expectedError string
+	}{
+		{
+			name:        "root workspace has no quota",
+			clusterName: "root",
+			a:           createAttr(namespaces, ""),
+		},
+		{
+			name:        "unknown workspace has no quota",
+			clusterName: "root:org:unknown",
+			a:           createAttr(namespaces, ""),
+		},
+		{
+			name:        "workspace of unknown type has no quota",
+			clusterName: "root:org:untyped",
+			a:           createAttr(namespaces, ""),
+		},
+		{
+			name:        "workspace of type without quota",
+			clusterName: "root:org:unlimited",
+			a:           createAttr(namespaces, ""),
+		},
+		{
+			name:        "resource without limit",
+			clusterName: "root:org:limited",
+			a:           createAttr(configmaps, ""),
+		},
+		{
+			name:        "subresource is not limited",
+			clusterName: "root:org:limited",
+			a:           createAttr(namespaces, "finalize"),
+		},
+		{
+			name:          "limit of zero rejects without counting",
+			clusterName:   "root:org:limited",
+			a:             createAttr(namespaces, ""),
+			expectedError: "exceeded quota of ClusterWorkspaceType root:limited: at most 0 namespaces allowed",
+		},
+		{
+			name:        "below the limit",
+			clusterName: "root:org:limited",
+			a:           createAttr(cowboys, ""),
+			count:       1,
+			wantCounted: true,
+		},
+		{
+			name:          "at the limit",
+			clusterName:   "root:org:limited",
+			a:             createAttr(cowboys, ""),
+			count:         2,
+			wantCounted:   true,
+			expectedError: "exceeded quota of ClusterWorkspaceType root:limited: at most 2 cowboys.wildwest.dev allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counted := false
+			o := &clusterWorkspaceTypeQuota{
+				Handler: admission.NewHandler(admission.Create),
+				getClusterWorkspace: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+					if ws, found := workspaces[name]; found && clusterName == logicalcluster.New("root:org") {
+						return ws, nil
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), name)
+				},
+				getClusterWorkspaceType: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+					for _, cwt := range []*tenancyv1alpha1.ClusterWorkspaceType{typeWithQuota, typeWithoutQuota} {
+						if cwt.Name == name && clusterName == tenancyv1alpha1.RootCluster {
+							return cwt, nil
+						}
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspacetypes"), name)
+				},
+				countObjects: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, limit int64) (int64, error) {
+					require.Equal(t, tt.clusterName, clusterName.String())
+					require.Equal(t, tt.a.GetResource(), gvr)
+					counted = true
+					return tt.count, nil
+				},
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New(tt.clusterName)})
+			err := o.Validate(ctx, tt.a, nil)
+			require.Equal(t, tt.wantCounted, counted, "counted")
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, apierrors.IsForbidden(err), "expected forbidden, got %v", err)
+			require.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestMaxObjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		quota       *tenancyv1alpha1.ClusterWorkspaceTypeQuota
+		gr          schema.GroupResource
+		wantMax     int64
+		wantLimited bool
+	}{
+		{name: "nil quota", gr: corev1.Resource("namespaces")},
+		{name: "empty quota", quota: &tenancyv1alpha1.ClusterWorkspaceTypeQuota{}, gr: corev1.Resource("namespaces")},
+		{
+			name:        "namespaces",
+			quota:       &tenancyv1alpha1.ClusterWorkspaceTypeQuota{MaxNamespaces: pointer.Int64(10)},
+			gr:          corev1.Resource("namespaces"),
+			wantMax:     10,
+			wantLimited: true,
+		},
+		{
+			name:        "child workspaces",
+			quota:       &tenancyv1alpha1.ClusterWorkspaceTypeQuota{MaxChildWorkspaces: pointer.Int64(0)},
+			gr:          tenancyv1alpha1.Resource("clusterworkspaces"),
+			wantLimited: true,
+		},
+		{
+			name: "objects of the same resource in another group are not limited",
+			quota: &tenancyv1alpha1.ClusterWorkspaceTypeQuota{
+				MaxObjects: []tenancyv1alpha1.ResourceObjectQuota{{Resource: "cowboys", Max: 5}},
+			},
+			gr: schema.GroupResource{Group: "wildwest.dev", Resource: "cowboys"},
+		},
+		{
+			name: "smallest limit of the same resource wins",
+			quota: &tenancyv1alpha1.ClusterWorkspaceTypeQuota{
+				MaxNamespaces: pointer.Int64(10),
+				MaxObjects: []tenancyv1alpha1.ResourceObjectQuota{
+					{Resource: "namespaces", Max: 3},
+					{Resource: "namespaces", Max: 7},
+				},
+			},
+			gr:          corev1.Resource("namespaces"),
+			wantMax:     3,
+			wantLimited: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			max, limited := MaxObjects(tt.quota, tt.gr)
+			require.Equal(t, tt.wantLimited, limited, "limited")
+			require.Equal(t, tt.wantMax, max)
+		})
+	}
+}
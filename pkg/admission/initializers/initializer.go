@@ -20,6 +20,7 @@ import (
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/admission/initializer"
 	quota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/dynamic"
 	kubernetesclient "k8s.io/client-go/kubernetes"
 
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
@@ -66,6 +67,26 @@ func (i *kubeClusterClientInitializer) Initialize(plugin admission.Interface) {
 	}
 }
 
+// NewDynamicClusterClientInitializer returns an admission plugin initializer that injects
+// a dynamic cluster client into admission plugins.
+func NewDynamicClusterClientInitializer(
+	dynamicClusterClient dynamic.ClusterInterface,
+) *dynamicClusterClientInitializer {
+	return &dynamicClusterClientInitializer{
+		dynamicClusterClient: dynamicClusterClient,
+	}
+}
+
+type dynamicClusterClientInitializer struct {
+	dynamicClusterClient dynamic.ClusterInterface
+}
+
+func (i *dynamicClusterClientInitializer) Initialize(plugin admission.Interface) {
+	if wants, ok := plugin.(WantsDynamicClusterClient); ok {
+		wants.SetDynamicClusterClient(i.dynamicClusterClient)
+	}
+}
+
 // NewKcpClusterClientInitializer returns an admission plugin initializer that injects
 // a kcp cluster client into admission plugins.
 func NewKcpClusterClientInitializer(
@@ -17,6 +17,7 @@ limitations under the License.
 package initializers
 
 import (
+	"k8s.io/client-go/dynamic"
 	kubernetesclient "k8s.io/client-go/kubernetes"
 
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
@@ -35,6 +36,12 @@ type WantsKubeClusterClient interface {
 	SetKubeClusterClient(kubernetesclient.ClusterInterface)
 }
 
+// WantsDynamicClusterClient interface should be implemented by admission plugins
+// that want to have a dynamic cluster client injected.
+type WantsDynamicClusterClient interface {
+	SetDynamicClusterClient(dynamic.ClusterInterface)
+}
+
 // WantsKcpClusterClient interface should be implemented by admission plugins
 // that want to have a kcp cluster client injected.
 type WantsKcpClusterClient interface {
@@ -26,11 +26,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/admission/initializer"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	kubernetesinformers "k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/permissionclaim"
 )
 
@@ -48,13 +52,17 @@ type mutatingPermissionClaims struct {
 	*admission.Handler
 
 	apiBindingsHasSynced cache.InformerSynced
+	namespacesHasSynced  cache.InformerSynced
 
+	apiBindingInformer     apisinformers.APIBindingInformer
+	namespaceInformer      coreinformers.NamespaceInformer
 	permissionClaimLabeler *permissionclaim.Labeler
 }
 
 var _ admission.MutationInterface = &mutatingPermissionClaims{}
 var _ admission.ValidationInterface = &mutatingPermissionClaims{}
 var _ admission.InitializationValidator = &mutatingPermissionClaims{}
+var _ initializer.WantsExternalKubeInformerFactory = &mutatingPermissionClaims{}
 
 // NewMutatingPermissionClaims creates a mutating admission plugin that is responsible for labeling objects
 // according to permission claims. or every creation and update request, we will determine the bindings
@@ -67,7 +75,7 @@ func NewMutatingPermissionClaims() admission.MutationInterface {
 
 	p.SetReadyFunc(
 		func() bool {
-			return p.apiBindingsHasSynced()
+			return p.apiBindingsHasSynced() && p.namespacesHasSynced()
 		},
 	)
 
@@ -89,7 +97,7 @@ func (m *mutatingPermissionClaims) Admit(ctx context.Context, a admission.Attrib
 		return err
 	}
 
-	expectedLabels, err := m.permissionClaimLabeler.LabelsFor(ctx, clusterName, a.GetResource().GroupResource(), a.GetName())
+	expectedLabels, err := m.permissionClaimLabeler.LabelsFor(ctx, clusterName, a.GetResource().GroupResource(), a.GetNamespace(), a.GetName())
 	if err != nil {
 		return err
 	}
@@ -130,7 +138,7 @@ func (m *mutatingPermissionClaims) Validate(ctx context.Context, a admission.Att
 		return err
 	}
 
-	expectedLabels, err := m.permissionClaimLabeler.LabelsFor(ctx, clusterName, a.GetResource().GroupResource(), a.GetName())
+	expectedLabels, err := m.permissionClaimLabeler.LabelsFor(ctx, clusterName, a.GetResource().GroupResource(), a.GetNamespace(), a.GetName())
 	if err != nil {
 		return err
 	}
@@ -165,16 +173,34 @@ func (m *mutatingPermissionClaims) Validate(ctx context.Context, a admission.Att
 // SetKcpInformers implements the WantsExternalKcpInformerFactory interface.
 func (m *mutatingPermissionClaims) SetKcpInformers(f kcpinformers.SharedInformerFactory) {
 	m.apiBindingsHasSynced = f.Apis().V1alpha1().APIBindings().Informer().HasSynced
+	m.apiBindingInformer = f.Apis().V1alpha1().APIBindings()
+	m.setLabeler()
+}
 
-	m.permissionClaimLabeler = permissionclaim.NewLabeler(
-		f.Apis().V1alpha1().APIBindings(),
-	)
+// SetExternalKubeInformerFactory implements the WantsExternalKubeInformerFactory interface.
+func (m *mutatingPermissionClaims) SetExternalKubeInformerFactory(f kubernetesinformers.SharedInformerFactory) {
+	m.namespacesHasSynced = f.Core().V1().Namespaces().Informer().HasSynced
+	m.namespaceInformer = f.Core().V1().Namespaces()
+	m.setLabeler()
+}
+
+// setLabeler creates the labeler once the informers of both the kcp and the kube informer
+// factory are set.
+func (m *mutatingPermissionClaims) setLabeler() {
+	if m.apiBindingInformer == nil || m.namespaceInformer == nil {
+		return
+	}
+	m.permissionClaimLabeler = permissionclaim.NewLabeler(m.apiBindingInformer, m.namespaceInformer)
 }
 
+// ValidateInitialization implements the InitializationValidator interface.
 func (m *mutatingPermissionClaims) ValidateInitialization() error {
 	if m.apiBindingsHasSynced == nil {
 		return errors.New("missing apiBindingsHasSynced")
 	}
+	if m.namespacesHasSynced == nil {
+		return errors.New("missing namespacesHasSynced")
+	}
 	if m.permissionClaimLabeler == nil {
 		return errors.New("missing permissionClaimLabeler")
 	}
@@ -47,6 +47,7 @@ import (
 	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspaceshard"
 	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetype"
 	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetypeexists"
+	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetypequota"
 	"github.com/kcp-dev/kcp/pkg/admission/crdnooverlappinggvr"
 	"github.com/kcp-dev/kcp/pkg/admission/kubequota"
 	kcpmutatingwebhook "github.com/kcp-dev/kcp/pkg/admission/mutatingwebhook"
@@ -68,6 +69,7 @@ var AllOrderedPlugins = beforeWebhooks(kubeapiserveroptions.AllOrderedPlugins,
 	clusterworkspaceshard.PluginName,
 	clusterworkspacetype.PluginName,
 	clusterworkspacetypeexists.PluginName,
+	clusterworkspacetypequota.PluginName,
 	apibinding.PluginName,
 	apibindingfinalizer.PluginName,
 	kcpvalidatingwebhook.PluginName,
@@ -101,6 +103,7 @@ func RegisterAllKcpAdmissionPlugins(plugins *admission.Plugins) {
 	clusterworkspaceshard.Register(plugins)
 	clusterworkspacetype.Register(plugins)
 	clusterworkspacetypeexists.Register(plugins)
+	clusterworkspacetypequota.Register(plugins)
 	apiresourceschema.Register(plugins)
 	apibinding.Register(plugins)
 	apibindingfinalizer.Register(plugins)
@@ -129,6 +132,7 @@ var defaultOnPluginsInKcp = sets.NewString(
 	clusterworkspaceshard.PluginName,
 	clusterworkspacetype.PluginName,
 	clusterworkspacetypeexists.PluginName,
+	clusterworkspacetypequota.PluginName,
 	apiresourceschema.PluginName,
 	apibinding.PluginName,
 	apibindingfinalizer.PluginName,
@@ -52,6 +52,12 @@ func CRDToAPIResourceSchema(crd *apiextensionsv1.CustomResourceDefinition, prefi
 		},
 	}
 
+	// None is the default conversion and does not have to be carried over. Conversion webhooks are not supported
+	// until they can be routed through the APIExport virtual workspace.
+	if conversion := crd.Spec.Conversion; conversion != nil && conversion.Strategy != apiextensionsv1.NoneConverter {
+		return nil, field.NotSupported(field.NewPath("spec", "conversion", "strategy"), conversion.Strategy, []string{string(apiextensionsv1.NoneConverter)})
+	}
+
 	for i := range crd.Spec.Versions {
 		crdVersion := crd.Spec.Versions[i]
 
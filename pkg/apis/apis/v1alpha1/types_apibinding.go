@@ -78,8 +78,36 @@ type APIBindingSpec struct {
 	//
 	// +optional
 	PermissionClaims []AcceptablePermissionClaim `json:"permissionClaims,omitempty"`
+
+	// schemaUpdatePolicy determines what happens when the API service provider changes the
+	// latestResourceSchemas of the APIExport after the initial binding:
+	// - Automatic: the updated schemas are bound right away.
+	// - Manual: the updated schemas are only bound once spec.acceptedSchemaHash matches
+	//   status.exportSchemaHash. Until then, the BindingUpToDate condition is false with reason
+	//   SchemaUpdatePending.
+	//
+	// +optional
+	// +kubebuilder:default=Automatic
+	// +kubebuilder:validation:Enum=Automatic;Manual
+	SchemaUpdatePolicy SchemaUpdatePolicy `json:"schemaUpdatePolicy,omitempty"`
+
+	// acceptedSchemaHash accepts the resource schemas of the APIExport with the given hash, as
+	// shown in status.exportSchemaHash. It is only used with the Manual schemaUpdatePolicy.
+	//
+	// +optional
+	AcceptedSchemaHash string `json:"acceptedSchemaHash,omitempty"`
 }
 
+// SchemaUpdatePolicy determines how updates of the resource schemas of a bound APIExport are applied.
+type SchemaUpdatePolicy string
+
+const (
+	// SchemaUpdatePolicyAutomatic binds updated resource schemas right away.
+	SchemaUpdatePolicyAutomatic SchemaUpdatePolicy = "Automatic"
+	// SchemaUpdatePolicyManual binds updated resource schemas only after they have been accepted.
+	SchemaUpdatePolicyManual SchemaUpdatePolicy = "Manual"
+)
+
 // AcceptablePermissionClaim is a PermissionClaim that records if the user accepts or rejects it.
 type AcceptablePermissionClaim struct {
 	PermissionClaim `json:",inline"`
@@ -180,6 +208,13 @@ type APIBindingStatus struct {
 	// the binding to grant.
 	// +optional
 	ExportPermissionClaims []PermissionClaim `json:"exportPermissionClaims,omitempty"`
+
+	// exportSchemaHash is the hash of the latestResourceSchemas of the bound APIExport. With the
+	// Manual schemaUpdatePolicy, it differs from the hash of the bound schemas while an update
+	// is pending, and has to be copied to spec.acceptedSchemaHash to accept the update.
+	//
+	// +optional
+	ExportSchemaHash string `json:"exportSchemaHash,omitempty"`
 }
 
 // These are valid conditions of APIBinding.
@@ -214,6 +249,10 @@ const (
 	// has a naming conflict with other APIs.
 	NamingConflictsReason = "NamingConflicts"
 
+	// SchemaUpdatePendingReason is a reason for the BindingUpToDate condition that the APIExport has updated resource
+	// schemas which have not been accepted yet with the Manual schemaUpdatePolicy.
+	SchemaUpdatePendingReason = "SchemaUpdatePending"
+
 	// BindingResourceDeleteSuccess is a condition for APIBinding that indicates the resources relating this binding are deleted
 	// successfully when the APIBinding is deleting
 	BindingResourceDeleteSuccess conditionsv1alpha1.ConditionType = "BindingResourceDeleteSuccess"
@@ -20,7 +20,9 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
 )
@@ -149,6 +151,10 @@ type APIExportSpec struct {
 	// PermissionClaims must be accepted by the user's explicit acknowledgement. Hence, when claims
 	// change, the respecting objects are not visible immediately.
 	//
+	// PermissionClaims can be restricted to namespaces of the consumer workspace by name or by label
+	// selector. A restricted claim never gives access to cluster-scoped objects. PermissionClaims can
+	// also be restricted to a set of verbs, e.g. to read-only access.
+	//
 	// PermissionClaims overlapping with the APIExport resources are ignored.
 	//
 	// +optional
@@ -194,6 +200,51 @@ type PermissionClaim struct {
 	// Note that one must look this up for a particular KCP instance.
 	// +optional
 	IdentityHash string `json:"identityHash,omitempty"`
+
+	// namespaces restricts the claim to objects in the given namespaces of the consumer
+	// workspace. For the namespaces resource itself, it restricts the claim to the namespaces
+	// with the given names.
+	//
+	// If both namespaces and namespaceSelector are set, an object is claimed if its namespace
+	// is listed or matches the selector. If neither is set, the claim applies to all objects
+	// of the resource.
+	//
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// namespaceSelector restricts the claim to objects in namespaces of the consumer workspace
+	// whose labels match the selector. For the namespaces resource itself, it restricts the
+	// claim to the matching namespaces.
+	//
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// verbs restricts the claim to the given verbs, e.g. get, list and watch for read-only
+	// access. "*" stands for all verbs. If verbs is empty, the claim applies to all verbs.
+	//
+	// +optional
+	// +listType=set
+	Verbs []string `json:"verbs,omitempty"`
+}
+
+// AllowsVerb returns true if the claim grants access for the given verb.
+func (p PermissionClaim) AllowsVerb(verb string) bool {
+	if len(p.Verbs) == 0 {
+		return true
+	}
+	for _, v := range p.Verbs {
+		if v == "*" || v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNamespaceRestricted returns true if the claim is restricted to some namespaces of the
+// consumer workspace.
+func (p PermissionClaim) IsNamespaceRestricted() bool {
+	return len(p.Namespaces) > 0 || p.NamespaceSelector != nil
 }
 
 func (p PermissionClaim) String() string {
@@ -210,7 +261,10 @@ func (p PermissionClaim) String() string {
 func (p PermissionClaim) Equal(claim PermissionClaim) bool {
 	return p.Group == claim.Group &&
 		p.Resource == claim.Resource &&
-		p.IdentityHash == claim.IdentityHash
+		p.IdentityHash == claim.IdentityHash &&
+		sets.NewString(p.Namespaces...).Equal(sets.NewString(claim.Namespaces...)) &&
+		equality.Semantic.DeepEqual(p.NamespaceSelector, claim.NamespaceSelector) &&
+		sets.NewString(p.Verbs...).Equal(sets.NewString(claim.Verbs...))
 }
 
 // GroupResource identifies a resource.
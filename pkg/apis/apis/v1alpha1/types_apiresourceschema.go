@@ -67,14 +67,22 @@ type APIResourceSchemaSpec struct {
 
 	// versions is the API version of the defined custom resource.
 	//
-	// Note: the OpenAPI v3 schemas must be equal for all versions until CEL
-	//       version migration is supported.
+	// Note: the OpenAPI v3 schemas must be equal for all versions unless a
+	//       conversion webhook is configured.
 	//
 	// +required
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MinItems=1
 	Versions []APIResourceVersion `json:"versions"`
+
+	// conversion defines conversion settings for the defined custom resource.
+	// Only the None strategy is supported. Conversion webhooks are not
+	// supported until they can be routed through the APIExport virtual
+	// workspace.
+	//
+	// +optional
+	Conversion *apiextensionsv1.CustomResourceConversion `json:"conversion,omitempty"`
 }
 
 // APIResourceVersion describes one API version of a resource.
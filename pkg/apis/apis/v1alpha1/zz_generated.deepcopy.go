@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
@@ -97,7 +98,9 @@ func (in *APIBindingSpec) DeepCopyInto(out *APIBindingSpec) {
 	if in.PermissionClaims != nil {
 		in, out := &in.PermissionClaims, &out.PermissionClaims
 		*out = make([]AcceptablePermissionClaim, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -137,12 +140,16 @@ func (in *APIBindingStatus) DeepCopyInto(out *APIBindingStatus) {
 	if in.AppliedPermissionClaims != nil {
 		in, out := &in.AppliedPermissionClaims, &out.AppliedPermissionClaims
 		*out = make([]PermissionClaim, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.ExportPermissionClaims != nil {
 		in, out := &in.ExportPermissionClaims, &out.ExportPermissionClaims
 		*out = make([]PermissionClaim, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -239,7 +246,9 @@ func (in *APIExportSpec) DeepCopyInto(out *APIExportSpec) {
 	if in.PermissionClaims != nil {
 		in, out := &in.PermissionClaims, &out.PermissionClaims
 		*out = make([]PermissionClaim, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -353,6 +362,11 @@ func (in *APIResourceSchemaSpec) DeepCopyInto(out *APIResourceSchemaSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conversion != nil {
+		in, out := &in.Conversion, &out.Conversion
+		*out = new(v1.CustomResourceConversion)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -397,7 +411,7 @@ func (in *APIResourceVersion) DeepCopy() *APIResourceVersion {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AcceptablePermissionClaim) DeepCopyInto(out *AcceptablePermissionClaim) {
 	*out = *in
-	out.PermissionClaim = in.PermissionClaim
+	in.PermissionClaim.DeepCopyInto(&out.PermissionClaim)
 	return
 }
 
@@ -548,6 +562,21 @@ func (in *MaximalPermissionPolicy) DeepCopy() *MaximalPermissionPolicy {
 func (in *PermissionClaim) DeepCopyInto(out *PermissionClaim) {
 	*out = *in
 	out.GroupResource = in.GroupResource
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verbs != nil {
+		in, out := &in.Verbs, &out.Verbs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+)
+
+// ContainsCluster returns true if cluster is one of the clusters in set.
+func ContainsCluster(set []logicalcluster.Name, cluster logicalcluster.Name) bool {
+	for _, c := range set {
+		if c == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterSet is a set of logical cluster names with O(1) membership checks.
+type ClusterSet map[logicalcluster.Name]struct{}
+
+// NewClusterSet creates a ClusterSet from a list of clusters.
+func NewClusterSet(clusters ...logicalcluster.Name) ClusterSet {
+	s := ClusterSet{}
+	s.Add(clusters...)
+	return s
+}
+
+// Add adds clusters to the set. Adding a cluster that is already in the set is
+// a no-op.
+func (s ClusterSet) Add(clusters ...logicalcluster.Name) ClusterSet {
+	for _, c := range clusters {
+		s[c] = struct{}{}
+	}
+	return s
+}
+
+// Has returns true if cluster is contained in the set.
+func (s ClusterSet) Has(cluster logicalcluster.Name) bool {
+	_, ok := s[cluster]
+	return ok
+}
+
+// Len returns the number of clusters in the set.
+func (s ClusterSet) Len() int {
+	return len(s)
+}
+
+// List returns the contents of the set as a slice sorted by cluster name.
+func (s ClusterSet) List() []logicalcluster.Name {
+	ret := make([]logicalcluster.Name, 0, len(s))
+	for c := range s {
+		ret = append(ret, c)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// Difference returns the clusters of s that are not in other as a new set.
+func (s ClusterSet) Difference(other ClusterSet) ClusterSet {
+	ret := ClusterSet{}
+	for c := range s {
+		if !other.Has(c) {
+			ret.Add(c)
+		}
+	}
+	return ret
+}
+
+// ClusterSetDiff compares a desired with an actual list of clusters and
+// returns the clusters missing from actual and those in actual that are not
+// desired, both deduplicated and sorted by cluster name.
+func ClusterSetDiff(desired, actual []logicalcluster.Name) (toAdd, toRemove []logicalcluster.Name) {
+	desiredSet, actualSet := NewClusterSet(desired...), NewClusterSet(actual...)
+	return desiredSet.Difference(actualSet).List(), actualSet.Difference(desiredSet).List()
+}
+
+// SortClusters sorts clusters in place in hierarchical pre-order: clusters are
+// compared segment by segment, segments are compared lexicographically, and a
+// cluster sorts directly before its descendants. For example root, root:a,
+// root:a:b, root:b, root-a, system. Equal clusters keep their relative order.
+func SortClusters(clusters []logicalcluster.Name) {
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return lessCluster(clusters[i], clusters[j])
+	})
+}
+
+func lessCluster(a, b logicalcluster.Name) bool {
+	aSegments, bSegments := splitCluster(a), splitCluster(b)
+	for i := 0; i < len(aSegments) && i < len(bSegments); i++ {
+		if aSegments[i] != bSegments[i] {
+			return aSegments[i] < bSegments[i]
+		}
+	}
+	return len(aSegments) < len(bSegments)
+}
+
+// DescendantsWithin returns the clusters of all that are base itself or one of
+// its descendants at most maxDepth levels below base, in the order of all.
+// A maxDepth of 0 selects only base.
+func DescendantsWithin(base logicalcluster.Name, all []logicalcluster.Name, maxDepth int) []logicalcluster.Name {
+	baseSegments, err := Segments(base)
+	if maxDepth < 0 || err != nil {
+		return nil
+	}
+	baseDepth := len(baseSegments)
+
+	var ret []logicalcluster.Name
+	for _, c := range all {
+		if hasSegmentPrefix(c, base) && len(splitCluster(c))-baseDepth <= maxDepth {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// RenderClusterTree renders clusters as an indented tree for CLI output. Each
+// cluster is printed on its own line by its last segment, indented by two
+// spaces per level below its root, in the order of SortClusters. Duplicates
+// are printed once. Ancestors that are not in clusters themselves are printed
+// as implied nodes to keep the tree connected, marked with "(implied)".
+func RenderClusterTree(clusters []logicalcluster.Name) (string, error) {
+	given := NewClusterSet()
+	all := NewClusterSet()
+	for _, c := range clusters {
+		if err := WalkAncestors(c, func(ancestor logicalcluster.Name) (bool, error) {
+			all.Add(ancestor)
+			return false, nil
+		}); err != nil {
+			return "", err
+		}
+		given.Add(c)
+	}
+
+	sorted := all.List()
+	SortClusters(sorted)
+
+	var b strings.Builder
+	for _, c := range sorted {
+		segments := splitCluster(c)
+		b.WriteString(strings.Repeat("  ", len(segments)-1))
+		b.WriteString(segments[len(segments)-1])
+		if !given.Has(c) {
+			b.WriteString(" (implied)")
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainsCluster(t *testing.T) {
+	set := []logicalcluster.Name{logicalcluster.New("root:a"), logicalcluster.New("root:b:c")}
+
+	require.True(t, ContainsCluster(set, logicalcluster.New("root:a")))
+	require.True(t, ContainsCluster(set, logicalcluster.New("root:b:c")))
+	require.False(t, ContainsCluster(set, logicalcluster.New("root:b")))
+	require.False(t, ContainsCluster(set, logicalcluster.New("root")))
+	require.False(t, ContainsCluster(nil, logicalcluster.New("root:a")))
+}
+
+func TestClusterSet(t *testing.T) {
+	s := NewClusterSet(logicalcluster.New("root:b"))
+	require.True(t, s.Has(logicalcluster.New("root:b")))
+	require.False(t, s.Has(logicalcluster.New("root:a")))
+
+	s.Add(logicalcluster.New("root:a"), logicalcluster.New("root:c:d"))
+	require.True(t, s.Has(logicalcluster.New("root:a")))
+	require.True(t, s.Has(logicalcluster.New("root:c:d")))
+	require.Equal(t, 3, s.Len())
+
+	s.Add(logicalcluster.New("root:a"))
+	require.Equal(t, 3, s.Len(), "adding a duplicate must not grow the set")
+
+	require.Equal(t, []logicalcluster.Name{
+		logicalcluster.New("root:a"),
+		logicalcluster.New("root:b"),
+		logicalcluster.New("root:c:d"),
+	}, s.List())
+
+	require.Empty(t, NewClusterSet().List())
+}
+
+func TestClusterSetDiff(t *testing.T) {
+	names := func(clusters ...string) []logicalcluster.Name {
+		ret := []logicalcluster.Name{}
+		for _, c := range clusters {
+			ret = append(ret, logicalcluster.New(c))
+		}
+		return ret
+	}
+	tests := []struct {
+		name         string
+		desired      []logicalcluster.Name
+		actual       []logicalcluster.Name
+		wantToAdd    []logicalcluster.Name
+		wantToRemove []logicalcluster.Name
+	}{
+		{name: "overlap", desired: names("root:c", "root:a", "root:b"), actual: names("root:b", "root:d", "root:a"), wantToAdd: names("root:c"), wantToRemove: names("root:d")},
+		{name: "disjoint", desired: names("root:b", "root:a"), actual: names("root:d", "root:c"), wantToAdd: names("root:a", "root:b"), wantToRemove: names("root:c", "root:d")},
+		{name: "equal with duplicates", desired: names("root:a", "root:a"), actual: names("root:a"), wantToAdd: names(), wantToRemove: names()},
+		{name: "empty desired", actual: names("root:a"), wantToAdd: names(), wantToRemove: names("root:a")},
+		{name: "empty actual", desired: names("root:a"), wantToAdd: names("root:a"), wantToRemove: names()},
+		{name: "both empty", wantToAdd: names(), wantToRemove: names()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toAdd, toRemove := ClusterSetDiff(tt.desired, tt.actual)
+			require.Equal(t, tt.wantToAdd, toAdd)
+			require.Equal(t, tt.wantToRemove, toRemove)
+		})
+	}
+}
+
+func TestSortClusters(t *testing.T) {
+	clusters := []logicalcluster.Name{
+		logicalcluster.New("root:b"),
+		logicalcluster.New("system"),
+		logicalcluster.New("root:a:b"),
+		logicalcluster.New("root-a"),
+		logicalcluster.New("root:a-b"),
+		logicalcluster.New("root"),
+		logicalcluster.New("root:a"),
+		logicalcluster.New("root:a:a"),
+		logicalcluster.New("system:foo"),
+	}
+	SortClusters(clusters)
+
+	// note that plain string ordering would put root:a-b before root:a:a
+	require.Equal(t, []logicalcluster.Name{
+		logicalcluster.New("root"),
+		logicalcluster.New("root:a"),
+		logicalcluster.New("root:a:a"),
+		logicalcluster.New("root:a:b"),
+		logicalcluster.New("root:a-b"),
+		logicalcluster.New("root:b"),
+		logicalcluster.New("root-a"),
+		logicalcluster.New("system"),
+		logicalcluster.New("system:foo"),
+	}, clusters)
+}
+
+func TestDescendantsWithin(t *testing.T) {
+	var all []logicalcluster.Name
+	for _, c := range []string{"root", "root:a", "root:a:b", "root:a:b:c", "root:a:b:c:d", "root:ab", "root:b", "root:b:a"} {
+		all = append(all, logicalcluster.New(c))
+	}
+	tests := []struct {
+		base     string
+		maxDepth int
+		want     []string
+	}{
+		{base: "root:a", maxDepth: 0, want: []string{"root:a"}},
+		{base: "root:a", maxDepth: 1, want: []string{"root:a", "root:a:b"}},
+		{base: "root:a", maxDepth: 2, want: []string{"root:a", "root:a:b", "root:a:b:c"}},
+		{base: "root:a", maxDepth: 10, want: []string{"root:a", "root:a:b", "root:a:b:c", "root:a:b:c:d"}},
+		{base: "root", maxDepth: 1, want: []string{"root", "root:a", "root:ab", "root:b"}},
+		{base: "root:x", maxDepth: 1, want: nil},
+		{base: "root:a", maxDepth: -1, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.base, tt.maxDepth), func(t *testing.T) {
+			var want []logicalcluster.Name
+			for _, c := range tt.want {
+				want = append(want, logicalcluster.New(c))
+			}
+			require.Equal(t, want, DescendantsWithin(logicalcluster.New(tt.base), all, tt.maxDepth))
+		})
+	}
+}
+
+func TestRenderClusterTree(t *testing.T) {
+	var clusters []logicalcluster.Name
+	for _, c := range []string{"root:b", "root:a:x:y", "root", "root:a", "root:b", "system:foo", "root:a:z"} {
+		clusters = append(clusters, logicalcluster.New(c))
+	}
+	got, err := RenderClusterTree(clusters)
+	require.NoError(t, err)
+	require.Equal(t, `root
+  a
+    x (implied)
+      y
+    z
+  b
+system (implied)
+  foo
+`, got)
+
+	got, err = RenderClusterTree(nil)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	_, err = RenderClusterTree([]logicalcluster.Name{logicalcluster.New("root:a"), logicalcluster.New("abc:def")})
+	require.Error(t, err)
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"context"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+)
+
+// clusterKey is the context key for the current logical cluster.
+type clusterKey struct{}
+
+// WithCluster returns a copy of ctx carrying cluster as the current logical
+// cluster.
+func WithCluster(ctx context.Context, cluster logicalcluster.Name) context.Context {
+	return context.WithValue(ctx, clusterKey{}, cluster)
+}
+
+// ClusterFromContext returns the current logical cluster carried by ctx, and
+// false if there is none.
+func ClusterFromContext(ctx context.Context) (logicalcluster.Name, bool) {
+	cluster, ok := ctx.Value(clusterKey{}).(logicalcluster.Name)
+	return cluster, ok
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterFromContext(t *testing.T) {
+	ctx := WithCluster(context.Background(), logicalcluster.New("root:foo"))
+	cluster, ok := ClusterFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, logicalcluster.New("root:foo"), cluster)
+
+	ctx = WithCluster(ctx, logicalcluster.New("root:bar"))
+	cluster, ok = ClusterFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, logicalcluster.New("root:bar"), cluster, "inner cluster must shadow the outer one")
+
+	cluster, ok = ClusterFromContext(context.Background())
+	require.False(t, ok)
+	require.True(t, cluster.Empty())
+
+	cluster, ok = ClusterFromContext(logicalcluster.WithCluster(context.Background(), logicalcluster.New("root:foo")))
+	require.False(t, ok, "the context key must be private to this package")
+	require.True(t, cluster.Empty())
+
+	type otherKey struct{}
+	cluster, ok = ClusterFromContext(context.WithValue(context.Background(), otherKey{}, logicalcluster.New("root:foo")))
+	require.False(t, ok)
+	require.True(t, cluster.Empty())
+}
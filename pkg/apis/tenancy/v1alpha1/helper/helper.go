@@ -17,11 +17,25 @@ limitations under the License.
 package helper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
@@ -35,21 +49,1156 @@ func IsValidCluster(cluster logicalcluster.Name) bool {
 		return false
 	}
 
-	return cluster.HasPrefix(v1alpha1.RootCluster) || cluster.HasPrefix(logicalcluster.New("system"))
+	for _, root := range RootClusters() {
+		if cluster.HasPrefix(root) {
+			return true
+		}
+	}
+	return false
+}
+
+// RootClusters returns the roots of the logical cluster hierarchies, i.e.
+// root and system.
+func RootClusters() []logicalcluster.Name {
+	return []logicalcluster.Name{v1alpha1.RootCluster, v1alpha1.SystemCluster}
 }
 
 // QualifiedObjectName builds a fully qualified identifier for an object
 // consisting of its logical cluster, namespace if applicable, and object
 // metadata name.
 func QualifiedObjectName(obj metav1.Object) string {
-	if len(obj.GetNamespace()) > 0 {
+	if IsNamespaced(obj) {
 		return fmt.Sprintf("%s|%s/%s", logicalcluster.From(obj), obj.GetNamespace(), obj.GetName())
 	}
 	return fmt.Sprintf("%s|%s", logicalcluster.From(obj), obj.GetName())
 }
 
+// HumanRef builds a readable reference to obj for messages like events, e.g.
+// workspace "root:acme:prod": configmap "ns/cm". The kind is taken from the
+// type meta of obj and falls back to "object". The workspace is left out if
+// obj has no cluster annotation.
+func HumanRef(obj metav1.Object) string {
+	kind := "object"
+	if o, ok := obj.(runtime.Object); ok && o.GetObjectKind().GroupVersionKind().Kind != "" {
+		kind = strings.ToLower(o.GetObjectKind().GroupVersionKind().Kind)
+	}
+	return humanRef(kind, obj)
+}
+
+// HumanRefForGVR is like HumanRef, but names the resource of obj by gvr, e.g.
+// workspace "root:acme:prod": deployments.apps "ns/web".
+func HumanRefForGVR(gvr schema.GroupVersionResource, obj metav1.Object) string {
+	return humanRef(gvr.GroupResource().String(), obj)
+}
+
+func humanRef(kind string, obj metav1.Object) string {
+	name := obj.GetName()
+	if IsNamespaced(obj) {
+		name = obj.GetNamespace() + "/" + name
+	}
+	if cluster := logicalcluster.From(obj); !cluster.Empty() {
+		return fmt.Sprintf("workspace %q: %s %q", cluster, kind, name)
+	}
+	return fmt.Sprintf("%s %q", kind, name)
+}
+
+// IsNamespaced returns true if obj lives in a namespace, i.e. whether
+// QualifiedObjectName includes a namespace for it.
+func IsNamespaced(obj metav1.Object) bool {
+	return len(obj.GetNamespace()) > 0
+}
+
+// SameNamespacedName returns true if a and b have the same namespace and name,
+// regardless of their logical clusters. It is the cluster-agnostic counterpart
+// of comparing QualifiedObjectNames.
+func SameNamespacedName(a, b metav1.Object) bool {
+	return a.GetNamespace() == b.GetNamespace() && a.GetName() == b.GetName()
+}
+
+// ParseQualifiedObjectName reverses QualifiedObjectName, splitting a
+// cluster|namespace/name or cluster|name identifier into its components.
+func ParseQualifiedObjectName(qualified string) (cluster logicalcluster.Name, namespace, name string, err error) {
+	clusterPart, objectPart, found := strings.Cut(qualified, "|")
+	if !found {
+		return logicalcluster.Name{}, "", "", fmt.Errorf("qualified object name %q is missing the cluster separator %q", qualified, "|")
+	}
+	if namespacePart, namePart, namespaced := strings.Cut(objectPart, "/"); namespaced {
+		namespace, name = namespacePart, namePart
+		if len(namespace) == 0 {
+			return logicalcluster.Name{}, "", "", fmt.Errorf("qualified object name %q has an empty namespace", qualified)
+		}
+	} else {
+		name = objectPart
+	}
+	if len(name) == 0 || strings.ContainsAny(name, "/|") {
+		return logicalcluster.Name{}, "", "", fmt.Errorf("qualified object name %q has an invalid object name", qualified)
+	}
+	return logicalcluster.New(clusterPart), namespace, name, nil
+}
+
+// QualifiedObjectNameWithShard builds a QualifiedObjectName prefixed with the
+// shard the object was read from, e.g. shard-1/root:foo|ns/name. The shard
+// prefix is omitted when shard is empty.
+func QualifiedObjectNameWithShard(shard string, obj metav1.Object) string {
+	if len(shard) == 0 {
+		return QualifiedObjectName(obj)
+	}
+	return shard + "/" + QualifiedObjectName(obj)
+}
+
+// ParseQualifiedObjectNameWithShard reverses QualifiedObjectNameWithShard. The
+// returned shard is empty if the identifier carries no shard prefix.
+func ParseQualifiedObjectNameWithShard(qualified string) (shard string, cluster logicalcluster.Name, namespace, name string, err error) {
+	clusterPart, _, found := strings.Cut(qualified, "|")
+	if !found {
+		return "", logicalcluster.Name{}, "", "", fmt.Errorf("qualified object name %q is missing the cluster separator %q", qualified, "|")
+	}
+	// logical cluster names never contain a slash, so one before the cluster
+	// separator can only terminate the shard.
+	if i := strings.Index(clusterPart, "/"); i >= 0 {
+		shard = clusterPart[:i]
+		if len(shard) == 0 {
+			return "", logicalcluster.Name{}, "", "", fmt.Errorf("qualified object name %q has an empty shard", qualified)
+		}
+		qualified = qualified[i+1:]
+	}
+	cluster, namespace, name, err = ParseQualifiedObjectName(qualified)
+	if err != nil {
+		return "", logicalcluster.Name{}, "", "", err
+	}
+	return shard, cluster, namespace, name, nil
+}
+
 // WorkspaceLabelSelector builds a label selector for objects associated with a
 // given workspace.
 func WorkspaceLabelSelector(name string) string {
 	return fmt.Sprintf("%s=%s", v1beta1.WorkspaceNameLabel, name)
 }
+
+// WorkspaceLabelSelectorValidated is like WorkspaceLabelSelector, but fails if
+// name is not accepted as a label value by the server.
+func WorkspaceLabelSelectorValidated(name string) (string, error) {
+	if err := ValidateWorkspaceNameForLabel(name); err != nil {
+		return "", err
+	}
+	return WorkspaceLabelSelector(name), nil
+}
+
+// ValidateWorkspaceNameForLabel checks that name is a valid value of the
+// workspaces.kcp.dev/name label.
+func ValidateWorkspaceNameForLabel(name string) error {
+	if errs := validation.IsValidLabelValue(name); len(errs) > 0 {
+		return fmt.Errorf("workspace name %q is not a valid %s label value: %s", name, v1beta1.WorkspaceNameLabel, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// WorkspaceNamesInSelector builds a label selector for objects associated with
+// any of the given workspaces. The names are deduplicated and sorted such that
+// the selector is deterministic.
+func WorkspaceNamesInSelector(names ...string) (labels.Selector, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one workspace name is required")
+	}
+	requirement, err := labels.NewRequirement(v1beta1.WorkspaceNameLabel, selection.In, sets.NewString(names...).List())
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
+
+// ClusterFieldName is the field by which stores indexing objects by the
+// field rather than by label refer to the logical cluster of an object.
+const ClusterFieldName = "spec.cluster"
+
+// ClusterFieldSelector builds a field selector for objects belonging to a
+// given cluster, the field based counterpart of WorkspaceLabelSelector.
+func ClusterFieldSelector(cluster logicalcluster.Name) (fields.Selector, error) {
+	if !IsValidCluster(cluster) {
+		return nil, fmt.Errorf("invalid cluster %q", cluster)
+	}
+	return fields.OneTermEqualSelector(ClusterFieldName, cluster.String()), nil
+}
+
+// ValidateWorkspaceSelector checks that selector parses as a label selector,
+// and that its requirements on the workspace name label can be satisfied at
+// the same time. For example, a selector requiring the label to be both a and
+// b can never match and is rejected.
+func ValidateWorkspaceSelector(selector string) error {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+	requirements, _ := parsed.Requirements()
+
+	var allowed sets.String // nil means unrestricted
+	excluded := sets.NewString()
+	mustExist, mustNotExist := false, false
+	for _, r := range requirements {
+		if r.Key() != v1beta1.WorkspaceNameLabel {
+			continue
+		}
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			mustExist = true
+			if allowed == nil {
+				allowed = r.Values()
+			} else {
+				allowed = allowed.Intersection(r.Values())
+			}
+		case selection.NotEquals, selection.NotIn:
+			excluded = excluded.Union(r.Values())
+		case selection.Exists:
+			mustExist = true
+		case selection.DoesNotExist:
+			mustNotExist = true
+		}
+	}
+	if mustExist && mustNotExist {
+		return fmt.Errorf("selector %q both requires and forbids the %s label", selector, v1beta1.WorkspaceNameLabel)
+	}
+	if allowed != nil && allowed.Difference(excluded).Len() == 0 {
+		return fmt.Errorf("selector %q has contradictory requirements on the %s label", selector, v1beta1.WorkspaceNameLabel)
+	}
+	return nil
+}
+
+// WorkspaceNameLabels builds the label set to put on objects associated with
+// a given workspace, i.e. the labels matched by WorkspaceLabelSelector.
+func WorkspaceNameLabels(name string) labels.Set {
+	return labels.Set{v1beta1.WorkspaceNameLabel: name}
+}
+
+// MergeWorkspaceLabels returns a copy of existing with the workspace name label
+// set to name, overriding any previous value. existing is not modified.
+func MergeWorkspaceLabels(existing map[string]string, name string) map[string]string {
+	return labels.Merge(existing, WorkspaceNameLabels(name))
+}
+
+// TruncateCluster returns the prefix of cluster with at most depth segments
+// below its root, e.g. root:org:team:proj truncated to depth 1 is root:org.
+// A depth of 0 returns just the root, and clusters that are already shallower
+// than depth are returned unchanged. An empty name is returned if cluster is
+// not valid or depth is negative.
+func TruncateCluster(cluster logicalcluster.Name, depth int) logicalcluster.Name {
+	segments, err := Segments(cluster)
+	if depth < 0 || err != nil {
+		return logicalcluster.Name{}
+	}
+
+	if len(segments) <= depth+1 {
+		return cluster
+	}
+	return logicalcluster.New(strings.Join(segments[:depth+1], ":"))
+}
+
+// ValidateClusterLength checks that no segment of cluster is longer than
+// maxSegment characters and that the whole name is not longer than maxTotal
+// characters. The returned error names the violated limit, and the offending
+// segment for segment violations. It does not check the naming rules of
+// IsValidCluster.
+func ValidateClusterLength(cluster logicalcluster.Name, maxSegment, maxTotal int) error {
+	for i, segment := range splitCluster(cluster) {
+		if len(segment) > maxSegment {
+			return fmt.Errorf("segment %d %q of cluster %q is %d characters long, exceeding the maximum segment length of %d", i, segment, cluster, len(segment), maxSegment)
+		}
+	}
+	if l := len(cluster.String()); l > maxTotal {
+		return fmt.Errorf("cluster %q is %d characters long, exceeding the maximum length of %d", cluster, l, maxTotal)
+	}
+	return nil
+}
+
+// WalkAncestors calls fn for cluster and then for each of its ancestors up to
+// and including the root, deepest first. The walk ends early when fn returns
+// stop or an error, and the error is returned.
+func WalkAncestors(cluster logicalcluster.Name, fn func(logicalcluster.Name) (stop bool, err error)) error {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return err
+	}
+	for i := len(segments); i > 0; i-- {
+		stop, err := fn(logicalcluster.New(strings.Join(segments[:i], ":")))
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// DivergencePoint compares the segments of two clusters and returns their
+// longest common ancestor, together with the segments of a and b below it.
+// The common ancestor is empty if a and b do not share a root, e.g. for
+// root:foo and system:foo.
+func DivergencePoint(a, b logicalcluster.Name) (common logicalcluster.Name, aRemainder, bRemainder []string) {
+	aSegments, bSegments := splitCluster(a), splitCluster(b)
+	i := 0
+	for i < len(aSegments) && i < len(bSegments) && aSegments[i] == bSegments[i] {
+		i++
+	}
+	return logicalcluster.New(strings.Join(aSegments[:i], ":")), aSegments[i:], bSegments[i:]
+}
+
+// Segments returns the segments of cluster in order, starting with its root,
+// e.g. root, acme and prod for root:acme:prod. Invalid clusters return an
+// error.
+func Segments(cluster logicalcluster.Name) ([]string, error) {
+	if !IsValidCluster(cluster) {
+		return nil, fmt.Errorf("invalid cluster %q", cluster)
+	}
+	return splitCluster(cluster), nil
+}
+
+// splitCluster returns the segments of cluster, or nil for the empty name. It
+// does not validate cluster, see Segments for that.
+func splitCluster(cluster logicalcluster.Name) []string {
+	if cluster.Empty() {
+		return nil
+	}
+	return strings.Split(cluster.String(), ":")
+}
+
+const (
+	// ClustersPathSegment precedes the cluster in /clusters/<cluster> URLs.
+	ClustersPathSegment = "clusters"
+	// ServicesPathSegment is the root of the virtual workspace URLs, e.g.
+	// /services/workspaces/<cluster>.
+	ServicesPathSegment = "services"
+	// WorkspacesPathSegment precedes the cluster in URLs of the workspaces
+	// virtual workspace.
+	WorkspacesPathSegment = "workspaces"
+	// ShardsPathSegment is reserved for addressing individual shards.
+	ShardsPathSegment = "shards"
+)
+
+// ReservedPathSegments returns the path segments kcp routes requests on.
+// Workspaces named like them would make cluster URLs ambiguous.
+func ReservedPathSegments() []string {
+	return []string{ClustersPathSegment, ServicesPathSegment, WorkspacesPathSegment, ShardsPathSegment}
+}
+
+// IsValidWorkspaceName indicates whether name is valid as a single segment of
+// a logical cluster name.
+func IsValidWorkspaceName(name string) bool {
+	return !strings.Contains(name, ":") && name != logicalcluster.Wildcard.String() && logicalcluster.New(name).IsValid()
+}
+
+// IsRoutingSafeWorkspaceName indicates whether name is a valid workspace name
+// that does not collide with one of the path segments kcp routes requests on,
+// such that URLs of the workspace can be parsed unambiguously.
+func IsRoutingSafeWorkspaceName(name string) bool {
+	if !IsValidWorkspaceName(name) {
+		return false
+	}
+	for _, reserved := range ReservedPathSegments() {
+		if name == reserved {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseClusterList parses a comma separated list of clusters as passed to a
+// command line flag, e.g. "root:a, root:b:c". Whitespace around entries is
+// ignored. The returned error aggregates all invalid and duplicate entries. An
+// empty or blank string yields no clusters.
+func ParseClusterList(s string) ([]logicalcluster.Name, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var clusters []logicalcluster.Name
+	var errs []error
+	seen := map[logicalcluster.Name]bool{}
+	for _, entry := range strings.Split(s, ",") {
+		cluster := logicalcluster.New(strings.TrimSpace(entry))
+		switch {
+		case !IsValidCluster(cluster):
+			errs = append(errs, fmt.Errorf("invalid cluster %q", cluster))
+		case seen[cluster]:
+			errs = append(errs, fmt.Errorf("duplicate cluster %q", cluster))
+		default:
+			seen[cluster] = true
+			clusters = append(clusters, cluster)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return clusters, nil
+}
+
+// InOrganization returns true if cluster is org or one of its descendants.
+// Unlike logicalcluster.Name.HasPrefix, only whole segments are matched, such
+// that root:acme2 is not in the organization root:acme. Invalid clusters are
+// in no organization.
+func InOrganization(cluster, org logicalcluster.Name) bool {
+	if !IsValidCluster(cluster) || !IsValidCluster(org) {
+		return false
+	}
+	return hasSegmentPrefix(cluster, org)
+}
+
+// hasSegmentPrefix returns true if cluster equals prefix or starts with prefix
+// followed by a segment separator.
+func hasSegmentPrefix(cluster, prefix logicalcluster.Name) bool {
+	return cluster == prefix || strings.HasPrefix(cluster.String(), prefix.String()+":")
+}
+
+// RebaseCluster replaces the root segment of cluster, root or system, with
+// newRoot, e.g. system:foo:bar rebased onto root is root:foo:bar.
+func RebaseCluster(cluster, newRoot logicalcluster.Name) (logicalcluster.Name, error) {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return logicalcluster.Name{}, err
+	}
+	if !ContainsCluster(RootClusters(), logicalcluster.New(segments[0])) {
+		return logicalcluster.Name{}, fmt.Errorf("cluster %q is not rooted at %s or %s", cluster, v1alpha1.RootCluster, v1alpha1.SystemCluster)
+	}
+
+	rebased := newRoot
+	for _, segment := range segments[1:] {
+		rebased = rebased.Join(segment)
+	}
+	if !IsValidCluster(rebased) {
+		return logicalcluster.Name{}, fmt.Errorf("rebasing cluster %q onto %q results in invalid cluster %q", cluster, newRoot, rebased)
+	}
+	return rebased, nil
+}
+
+// LeafName returns the last segment of cluster, e.g. prod for
+// root:acme:platform:prod. Bare roots like root have no leaf and return an
+// error, as do invalid clusters.
+func LeafName(cluster logicalcluster.Name) (string, error) {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return "", err
+	}
+	if len(segments) == 1 {
+		return "", fmt.Errorf("cluster %q is a root and has no leaf name", cluster)
+	}
+	return segments[len(segments)-1], nil
+}
+
+// CleanAndValidateCluster turns user or string-building input into a cluster
+// name. Surrounding whitespace and a single trailing colon are removed, and the
+// result must be a valid cluster. Whitespace or empty segments inside the name
+// are not repaired.
+func CleanAndValidateCluster(raw string) (logicalcluster.Name, error) {
+	cleaned := strings.TrimSuffix(strings.TrimSpace(raw), ":")
+	if cleaned == "" {
+		return logicalcluster.Name{}, fmt.Errorf("cluster name must not be empty")
+	}
+	cluster := logicalcluster.New(cleaned)
+	if !IsValidCluster(cluster) {
+		return logicalcluster.Name{}, fmt.Errorf("invalid cluster %q: must be a colon separated list of lower-case alphanumeric words, rooted at root or system", raw)
+	}
+	return cluster, nil
+}
+
+// homeShorthand is the leading segment referring to the home workspace of the
+// user, e.g. ~:projects.
+const homeShorthand = "~"
+
+// HasHomeShorthand returns true if raw starts with the ~ segment referring to
+// the home workspace of the user, i.e. it is ~ or starts with ~:.
+func HasHomeShorthand(raw string) bool {
+	return raw == homeShorthand || strings.HasPrefix(raw, homeShorthand+":")
+}
+
+// ExpandHomeShorthand replaces a leading ~ segment in raw with home, e.g.
+// ~:projects becomes <home>:projects. Input without the shorthand is passed
+// through. The result must be a valid cluster.
+func ExpandHomeShorthand(raw string, home logicalcluster.Name) (logicalcluster.Name, error) {
+	expanded := logicalcluster.New(raw)
+	if HasHomeShorthand(raw) {
+		if !IsValidCluster(home) {
+			return logicalcluster.Name{}, fmt.Errorf("invalid home workspace %q", home)
+		}
+		expanded = logicalcluster.New(home.String() + strings.TrimPrefix(raw, homeShorthand))
+	}
+	if !IsValidCluster(expanded) {
+		return logicalcluster.Name{}, fmt.Errorf("invalid cluster %q", expanded)
+	}
+	return expanded, nil
+}
+
+// ClusterGVRKey serializes a cluster and resource into a flat key of the form
+// <cluster>|<group>/<version>/<resource>, e.g. root:foo|apps/v1/deployments.
+// The group is empty for the core group, e.g. root:foo|/v1/pods. The key is
+// reversed by ParseClusterGVRKey.
+func ClusterGVRKey(cluster logicalcluster.Name, gvr schema.GroupVersionResource) string {
+	return fmt.Sprintf("%s|%s/%s/%s", cluster, gvr.Group, gvr.Version, gvr.Resource)
+}
+
+// ParseClusterGVRKey reverses ClusterGVRKey.
+func ParseClusterGVRKey(key string) (logicalcluster.Name, schema.GroupVersionResource, error) {
+	clusterPart, gvrPart, found := strings.Cut(key, "|")
+	if !found {
+		return logicalcluster.Name{}, schema.GroupVersionResource{}, fmt.Errorf("key %q is missing the cluster separator %q", key, "|")
+	}
+	parts := strings.SplitN(gvrPart, "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return logicalcluster.Name{}, schema.GroupVersionResource{}, fmt.Errorf("key %q must be of the form <cluster>|<group>/<version>/<resource>", key)
+	}
+	return logicalcluster.New(clusterPart), schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+}
+
+// ValidateMove computes the name of workspace source after moving it under
+// newParent, e.g. root:a:proj moved under root:b becomes root:b:proj. Moves of
+// a root, moves into the subtree of source itself and moves between the root
+// and system hierarchies are rejected.
+func ValidateMove(source, newParent logicalcluster.Name) (logicalcluster.Name, error) {
+	leaf, err := LeafName(source)
+	if err != nil {
+		return logicalcluster.Name{}, fmt.Errorf("cannot move %q: %w", source, err)
+	}
+	parentSegments, err := Segments(newParent)
+	if err != nil {
+		return logicalcluster.Name{}, fmt.Errorf("cannot move %q: invalid destination parent %q", source, newParent)
+	}
+	if hasSegmentPrefix(newParent, source) {
+		return logicalcluster.Name{}, fmt.Errorf("cannot move %q into its own subtree %q", source, newParent)
+	}
+	if sourceRoot := splitCluster(source)[0]; sourceRoot != parentSegments[0] {
+		return logicalcluster.Name{}, fmt.Errorf("cannot move %q from %s to %s hierarchy", source, sourceRoot, parentSegments[0])
+	}
+
+	newCluster := newParent.Join(leaf)
+	if !IsValidCluster(newCluster) {
+		return logicalcluster.Name{}, fmt.Errorf("cannot move %q: invalid destination %q", source, newCluster)
+	}
+	return newCluster, nil
+}
+
+// ClustersFromObjects groups objs by the logical cluster in their cluster
+// annotation. Objects whose annotation is missing or not a valid cluster are
+// returned separately, in their original order.
+func ClustersFromObjects(objs []metav1.Object) (map[logicalcluster.Name][]metav1.Object, []metav1.Object) {
+	byCluster := map[logicalcluster.Name][]metav1.Object{}
+	var invalid []metav1.Object
+	for _, obj := range objs {
+		cluster := logicalcluster.From(obj)
+		if !IsValidCluster(cluster) {
+			invalid = append(invalid, obj)
+			continue
+		}
+		byCluster[cluster] = append(byCluster[cluster], obj)
+	}
+	return byCluster, invalid
+}
+
+// ClusterTier is the role of a cluster in the tenancy model, derived from its
+// depth below the root.
+type ClusterTier string
+
+const (
+	// OrganizationTier clusters are direct children of a root, e.g. root:acme.
+	OrganizationTier ClusterTier = "Organization"
+	// TeamTier clusters are two levels below a root, e.g. root:acme:web.
+	TeamTier ClusterTier = "Team"
+	// ProjectTier clusters are three or more levels below a root, e.g.
+	// root:acme:web:prod.
+	ProjectTier ClusterTier = "Project"
+)
+
+// TierOf returns the tier of cluster by the depth convention documented on
+// the ClusterTier constants. Roots and invalid clusters have no tier.
+func TierOf(cluster logicalcluster.Name) (ClusterTier, error) {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return "", err
+	}
+	switch depth := len(segments) - 1; {
+	case depth == 0:
+		return "", fmt.Errorf("cluster %q is a root and has no tier", cluster)
+	case depth == 1:
+		return OrganizationTier, nil
+	case depth == 2:
+		return TeamTier, nil
+	default:
+		return ProjectTier, nil
+	}
+}
+
+// ClusterShardIndex maps cluster to one of shards buckets, e.g. to distribute
+// per-cluster work across workers. It uses the 32-bit FNV-1a hash of the
+// cluster name, which is fully specified, so the mapping is stable across
+// processes, architectures and Go versions.
+func ClusterShardIndex(cluster logicalcluster.Name, shards int) (int, error) {
+	if shards <= 0 {
+		return 0, fmt.Errorf("number of shards must be positive, got %d", shards)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(cluster.String())) //nolint:errcheck
+	return int(h.Sum32() % uint32(shards)), nil
+}
+
+// IsUniqueSibling returns true if name is not among the existing names of the
+// children of a parent.
+func IsUniqueSibling(name string, existing []string) bool {
+	for _, e := range existing {
+		if e == name {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateNewChild checks that a workspace name can be created below parent,
+// given the names of the existing children of parent. The name must be a valid
+// and routing safe workspace name, and must not exist yet.
+func ValidateNewChild(parent logicalcluster.Name, name string, existing []string) error {
+	if !IsValidCluster(parent) {
+		return fmt.Errorf("invalid parent cluster %q", parent)
+	}
+	if !IsValidWorkspaceName(name) {
+		return fmt.Errorf("invalid workspace name %q: must consist of lower-case alphanumerics and '-', start with a letter and end with an alphanumeric", name)
+	}
+	if !IsRoutingSafeWorkspaceName(name) {
+		return fmt.Errorf("workspace name %q is reserved", name)
+	}
+	if !IsUniqueSibling(name, existing) {
+		return fmt.Errorf("workspace %q already exists", parent.Join(name))
+	}
+	return nil
+}
+
+// NextSegmentUnder returns the segment directly below parent on the path to
+// descendant, e.g. team for root:org and root:org:team:proj. It returns false
+// if descendant is not a strict descendant of parent.
+func NextSegmentUnder(parent, descendant logicalcluster.Name) (string, bool) {
+	if parent.Empty() || !hasSegmentPrefix(descendant, parent) {
+		return "", false
+	}
+	parentSegments, descendantSegments := splitCluster(parent), splitCluster(descendant)
+	if len(descendantSegments) <= len(parentSegments) {
+		return "", false
+	}
+	return descendantSegments[len(parentSegments)], true
+}
+
+// ClusterFromSegments builds the cluster with the given segments below root,
+// e.g. root:org:team for root and the segments org and team. Each segment must
+// be a valid workspace name, and the error names the first one that is not.
+func ClusterFromSegments(root logicalcluster.Name, segments ...string) (logicalcluster.Name, error) {
+	if !IsValidCluster(root) {
+		return logicalcluster.Name{}, fmt.Errorf("invalid root cluster %q", root)
+	}
+	cluster := root
+	for i, segment := range segments {
+		if !IsValidWorkspaceName(segment) {
+			return logicalcluster.Name{}, fmt.Errorf("invalid segment %d %q below %q", i, segment, cluster)
+		}
+		cluster = cluster.Join(segment)
+	}
+	if !IsValidCluster(cluster) {
+		return logicalcluster.Name{}, fmt.Errorf("invalid cluster %q", cluster)
+	}
+	return cluster, nil
+}
+
+// RedactCluster hides the tenant identifying segments of cluster for logs,
+// e.g. root:acme:prod becomes root:***a1b2:***c3d4. The root and the depth are
+// kept, and every other segment is replaced by a short hash of the cluster up
+// to that segment, so the same cluster always redacts identically and support
+// can still correlate log lines. Invalid clusters are redacted as a whole.
+func RedactCluster(cluster logicalcluster.Name) string {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return redactedHash(cluster.String())
+	}
+	redacted := make([]string, len(segments))
+	redacted[0] = segments[0]
+	for i := 1; i < len(segments); i++ {
+		redacted[i] = redactedHash(strings.Join(segments[:i+1], ":"))
+	}
+	return strings.Join(redacted, ":")
+}
+
+// RedactQualifiedObjectName is like QualifiedObjectName, but with the cluster
+// redacted by RedactCluster.
+func RedactQualifiedObjectName(obj metav1.Object) string {
+	if IsNamespaced(obj) {
+		return fmt.Sprintf("%s|%s/%s", RedactCluster(logicalcluster.From(obj)), obj.GetNamespace(), obj.GetName())
+	}
+	return fmt.Sprintf("%s|%s", RedactCluster(logicalcluster.From(obj)), obj.GetName())
+}
+
+func redactedHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "***" + hex.EncodeToString(sum[:2])
+}
+
+// RelativePath returns the shell-like relative path to navigate from one
+// workspace to another, i.e. a number of ".." moves up followed by the
+// segments to move down, e.g. "../c" from root:a:b to root:a:c. The path is
+// "." if from and to are equal. Workspaces under different roots cannot be
+// reached relatively and return an error.
+func RelativePath(from, to logicalcluster.Name) (string, error) {
+	if !IsValidCluster(from) {
+		return "", fmt.Errorf("invalid cluster %q", from)
+	}
+	if !IsValidCluster(to) {
+		return "", fmt.Errorf("invalid cluster %q", to)
+	}
+	common, up, down := DivergencePoint(from, to)
+	if common.Empty() {
+		return "", fmt.Errorf("cluster %q is not under the same root as %q", to, from)
+	}
+	segments := make([]string, 0, len(up)+len(down))
+	for range up {
+		segments = append(segments, "..")
+	}
+	segments = append(segments, down...)
+	if len(segments) == 0 {
+		return ".", nil
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// MaxWorkspaceSegmentLength is the maximal length of a single segment of a
+// logical cluster name accepted by the server. Workspace names are stamped on
+// objects as the value of the workspaces.kcp.dev/name label, hence they are
+// bound by the maximal length of a label value.
+const MaxWorkspaceSegmentLength = validation.LabelValueMaxLength
+
+// WouldExceedSegmentLimit returns the first segment of cluster that is longer
+// than MaxWorkspaceSegmentLength, and whether there is one.
+func WouldExceedSegmentLimit(cluster logicalcluster.Name) (segment string, exceeds bool) {
+	for _, s := range splitCluster(cluster) {
+		if len(s) > MaxWorkspaceSegmentLength {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// WorkspaceLabelMatchesCluster indicates whether the workspaces.kcp.dev/name
+// label of obj matches the leaf name of the logical cluster obj lives in. It
+// returns an error if obj has no cluster annotation or no such label.
+func WorkspaceLabelMatchesCluster(obj metav1.Object) (bool, error) {
+	cluster := logicalcluster.From(obj)
+	if cluster.Empty() {
+		return false, fmt.Errorf("%s has no %s annotation", HumanRef(obj), logicalcluster.AnnotationKey)
+	}
+	leaf, err := LeafName(cluster)
+	if err != nil {
+		return false, err
+	}
+	value, ok := obj.GetLabels()[v1beta1.WorkspaceNameLabel]
+	if !ok {
+		return false, fmt.Errorf("%s has no %s label", HumanRef(obj), v1beta1.WorkspaceNameLabel)
+	}
+	return value == leaf, nil
+}
+
+// AncestorsOf returns the ancestors of cluster, nearest first and ending with
+// the root, e.g. root:a and root for root:a:b. A root has no ancestors.
+func AncestorsOf(cluster logicalcluster.Name) ([]logicalcluster.Name, error) {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var ancestors []logicalcluster.Name
+	for i := len(segments) - 1; i > 0; i-- {
+		ancestors = append(ancestors, logicalcluster.New(strings.Join(segments[:i], ":")))
+	}
+	return ancestors, nil
+}
+
+// AncestorNameSelector builds a label selector for objects associated with any
+// of the ancestor workspaces of cluster, by their leaf names. Roots have no
+// leaf name and are skipped, such that the selector matches nothing for
+// clusters directly below a root and for the roots themselves.
+func AncestorNameSelector(cluster logicalcluster.Name) (labels.Selector, error) {
+	ancestors, err := AncestorsOf(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ancestor := range ancestors {
+		if _, ok := ParentCluster(ancestor); !ok {
+			continue
+		}
+		name, err := LeafName(ancestor)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return labels.Nothing(), nil
+	}
+	return WorkspaceNamesInSelector(names...)
+}
+
+// ResolveInherited returns the value of the nearest of cluster and its
+// ancestors that has one in values, together with the cluster it is defined
+// at. Nothing is found for invalid clusters.
+func ResolveInherited(cluster logicalcluster.Name, values map[logicalcluster.Name]string) (value string, definedAt logicalcluster.Name, found bool) {
+	_ = WalkAncestors(cluster, func(current logicalcluster.Name) (bool, error) {
+		value, found = values[current]
+		if found {
+			definedAt = current
+		}
+		return found, nil
+	})
+	return value, definedAt, found
+}
+
+// IsReservedCluster indicates whether cluster cannot be created by users: the
+// roots themselves, clusters below the system root, and clusters whose leaf is
+// one of the reserved ClusterWorkspace names.
+func IsReservedCluster(cluster logicalcluster.Name) bool {
+	parent, leaf := cluster.Split()
+	if parent.Empty() {
+		return true
+	}
+	if hasSegmentPrefix(cluster, v1alpha1.SystemCluster) {
+		return true
+	}
+	for _, reserved := range v1alpha1.ClusterWorkspaceReservedNames() {
+		if leaf == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCreatableCluster checks that a workspace can be created for cluster,
+// i.e. that it is valid, not reserved and that its leaf name is routing safe.
+// The error describes the first check that failed.
+func ValidateCreatableCluster(cluster logicalcluster.Name) error {
+	if !IsValidCluster(cluster) {
+		return fmt.Errorf("invalid cluster %q", cluster)
+	}
+	if IsReservedCluster(cluster) {
+		return fmt.Errorf("cluster %q is reserved", cluster)
+	}
+	if _, leaf := cluster.Split(); !IsRoutingSafeWorkspaceName(leaf) {
+		return fmt.Errorf("workspace name %q of cluster %q collides with a path segment used for routing", leaf, cluster)
+	}
+	return nil
+}
+
+// WorkQueueKey builds a work queue key identifying obj across clusters. Unlike
+// QualifiedObjectName, every component is length-prefixed, e.g.
+// 8:root:foo2:ns4:name, such that the key can be split unambiguously by
+// SplitWorkQueueKey whatever characters the components contain.
+func WorkQueueKey(obj metav1.Object) string {
+	var b strings.Builder
+	for _, s := range []string{logicalcluster.From(obj).String(), obj.GetNamespace(), obj.GetName()} {
+		b.WriteString(strconv.Itoa(len(s)))
+		b.WriteByte(':')
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// SplitWorkQueueKey reverses WorkQueueKey.
+func SplitWorkQueueKey(key string) (cluster logicalcluster.Name, namespace, name string, err error) {
+	var parts []string
+	rest := key
+	for len(parts) < 3 {
+		length, remainder, found := strings.Cut(rest, ":")
+		if !found {
+			return logicalcluster.Name{}, "", "", fmt.Errorf("invalid work queue key %q", key)
+		}
+		n, err := strconv.Atoi(length)
+		if err != nil || n < 0 || n > len(remainder) {
+			return logicalcluster.Name{}, "", "", fmt.Errorf("invalid work queue key %q", key)
+		}
+		parts = append(parts, remainder[:n])
+		rest = remainder[n:]
+	}
+	if rest != "" {
+		return logicalcluster.Name{}, "", "", fmt.Errorf("invalid work queue key %q: trailing data", key)
+	}
+	return logicalcluster.New(parts[0]), parts[1], parts[2], nil
+}
+
+// IsDNSSubdomainSafe indicates whether cluster can be embedded into a DNS
+// subdomain, e.g. the group of a generated CRD, in the dot-joined form of
+// ClusterToDNSSubdomain. The colon-joined form never is.
+func IsDNSSubdomainSafe(cluster logicalcluster.Name) bool {
+	_, err := ClusterToDNSSubdomain(cluster)
+	return err == nil
+}
+
+// ClusterToDNSSubdomain returns cluster with its segments joined by dots, e.g.
+// root.acme.prod for root:acme:prod. It fails if the result is not a valid
+// RFC 1123 subdomain, which for valid clusters only happens if it is too long.
+func ClusterToDNSSubdomain(cluster logicalcluster.Name) (string, error) {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return "", err
+	}
+	subdomain := strings.Join(segments, ".")
+	if errs := validation.IsDNS1123Subdomain(subdomain); len(errs) > 0 {
+		return "", fmt.Errorf("cluster %q is not a valid DNS subdomain as %q: %s", cluster, subdomain, strings.Join(errs, ", "))
+	}
+	return subdomain, nil
+}
+
+// OrgAndWorkspace splits cluster into its organization, the direct child of its
+// root, and the path of the workspace below the organization, e.g. root:acme
+// and web:prod for root:acme:web:prod. The workspace is empty for the
+// organization itself. Roots and invalid clusters are in no organization.
+func OrgAndWorkspace(cluster logicalcluster.Name) (org logicalcluster.Name, workspace string, err error) {
+	segments, err := Segments(cluster)
+	if err != nil {
+		return logicalcluster.Name{}, "", err
+	}
+	if len(segments) < 2 {
+		return logicalcluster.Name{}, "", fmt.Errorf("cluster %q is a root and in no organization", cluster)
+	}
+	return logicalcluster.New(segments[0] + ":" + segments[1]), strings.Join(segments[2:], ":"), nil
+}
+
+// CommonOrganization returns the organization all clusters belong to, as
+// returned by OrgAndWorkspace. It returns false if clusters is empty, spans
+// multiple organizations, or contains roots or invalid clusters.
+func CommonOrganization(clusters []logicalcluster.Name) (org logicalcluster.Name, ok bool) {
+	for _, cluster := range clusters {
+		clusterOrg, _, err := OrgAndWorkspace(cluster)
+		if err != nil {
+			return logicalcluster.Name{}, false
+		}
+		if !org.Empty() && clusterOrg != org {
+			return logicalcluster.Name{}, false
+		}
+		org = clusterOrg
+	}
+	return org, !org.Empty()
+}
+
+// ResolveWorkspacePath resolves a workspace path entered by a user against the
+// current workspace. Absolute paths start with a root, e.g. root:acme:web, and
+// are only validated. Relative paths consist of "/" separated parts that are
+// either ".." for the parent or a ":" separated list of children to descend
+// into, e.g. "..", "../sibling" or "team:project". The errors are worded for
+// command line users.
+func ResolveWorkspacePath(current logicalcluster.Name, input string) (logicalcluster.Name, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return logicalcluster.Name{}, fmt.Errorf("workspace path must not be empty")
+	}
+
+	if first, _, _ := strings.Cut(input, ":"); ContainsCluster(RootClusters(), logicalcluster.New(first)) {
+		absolute := logicalcluster.New(input)
+		if !IsValidCluster(absolute) {
+			return logicalcluster.Name{}, fmt.Errorf("%q is not a valid workspace path, e.g. root:org:ws", input)
+		}
+		return absolute, nil
+	}
+
+	if !IsValidCluster(current) {
+		return logicalcluster.Name{}, fmt.Errorf("current workspace %q is invalid, cannot resolve the relative path %q", current, input)
+	}
+	resolved := current
+	for _, part := range strings.Split(strings.TrimSuffix(input, "/"), "/") {
+		switch part {
+		case ".":
+		case "..":
+			parent, ok := ParentCluster(resolved)
+			if !ok {
+				return logicalcluster.Name{}, fmt.Errorf("cannot resolve %q from %q: %s has no parent", input, current, resolved)
+			}
+			resolved = parent
+		default:
+			for _, segment := range strings.Split(part, ":") {
+				if !IsValidWorkspaceName(segment) {
+					return logicalcluster.Name{}, fmt.Errorf("cannot resolve %q from %q: %q is not a valid workspace name", input, current, segment)
+				}
+				resolved = resolved.Join(segment)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// ClusterFromObject returns the logical cluster of obj from its cluster
+// annotation, and an error if the annotation is missing or invalid.
+func ClusterFromObject(obj metav1.Object) (logicalcluster.Name, error) {
+	value, ok := obj.GetAnnotations()[logicalcluster.AnnotationKey]
+	if !ok {
+		return logicalcluster.Name{}, fmt.Errorf("%s has no %s annotation", HumanRef(obj), logicalcluster.AnnotationKey)
+	}
+	cluster := logicalcluster.New(value)
+	if !IsValidCluster(cluster) {
+		return logicalcluster.Name{}, fmt.Errorf("%s has an invalid %s annotation %q", HumanRef(obj), logicalcluster.AnnotationKey, value)
+	}
+	return cluster, nil
+}
+
+// HasValidCluster indicates whether obj has a valid cluster annotation, i.e.
+// whether ClusterFromObject succeeds, without building an error. It is meant
+// for hot paths like indexers.
+func HasValidCluster(obj metav1.Object) bool {
+	return IsValidCluster(logicalcluster.From(obj))
+}
+
+// MovePreservesOrg indicates whether moving workspace source under newParent
+// keeps it in the same organization, as returned by OrgAndWorkspace. Moves
+// across organizations, including moves that turn source into an
+// organization itself, return false. Cycles are not checked, see
+// ValidateMove for that.
+func MovePreservesOrg(source, newParent logicalcluster.Name) (bool, error) {
+	sourceOrg, _, err := OrgAndWorkspace(source)
+	if err != nil {
+		return false, fmt.Errorf("cannot move %q: %w", source, err)
+	}
+	if !IsValidCluster(newParent) {
+		return false, fmt.Errorf("cannot move %q: invalid destination parent %q", source, newParent)
+	}
+	_, leaf := source.Split()
+	newOrg, _, err := OrgAndWorkspace(newParent.Join(leaf))
+	if err != nil {
+		return false, fmt.Errorf("cannot move %q: %w", source, err)
+	}
+	return sourceOrg == newOrg, nil
+}
+
+// AggregateClusterErrors joins per-cluster validation errors into a single
+// error naming each cluster, sorted by cluster name so the message is
+// deterministic. Nil errors are skipped, and nil is returned if none remain.
+func AggregateClusterErrors(errs map[logicalcluster.Name]error) error {
+	clusters := make([]logicalcluster.Name, 0, len(errs))
+	for cluster, err := range errs {
+		if err != nil {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].String() < clusters[j].String()
+	})
+
+	aggregated := make([]error, 0, len(clusters))
+	for _, cluster := range clusters {
+		aggregated = append(aggregated, fmt.Errorf("cluster %q: %w", cluster, errs[cluster]))
+	}
+	return utilerrors.NewAggregate(aggregated)
+}
+
+// AssignShard places cluster on one of shards by ClusterShardIndex, such that
+// every component agrees on the placement for the same list of shards.
+func AssignShard(cluster logicalcluster.Name, shards []string) (string, error) {
+	if !IsValidCluster(cluster) {
+		return "", fmt.Errorf("invalid cluster %q", cluster)
+	}
+	if len(shards) == 0 {
+		return "", fmt.Errorf("cannot assign cluster %q to a shard: no shards", cluster)
+	}
+	i, err := ClusterShardIndex(cluster, len(shards))
+	if err != nil {
+		return "", err
+	}
+	return shards[i], nil
+}
+
+// ClustersFromQualifiedNames returns the distinct clusters referenced by names
+// in the format of QualifiedObjectName, sorted by cluster name. Names that do
+// not parse or reference an invalid cluster are returned as malformed, in
+// their original order.
+func ClustersFromQualifiedNames(names []string) (clusters []logicalcluster.Name, malformed []string) {
+	set := NewClusterSet()
+	for _, name := range names {
+		cluster, _, _, err := ParseQualifiedObjectName(name)
+		if err != nil || !IsValidCluster(cluster) {
+			malformed = append(malformed, name)
+			continue
+		}
+		set.Add(cluster)
+	}
+	return set.List(), malformed
+}
+
+// ParentCluster returns the parent of cluster, and false for roots and invalid
+// clusters.
+func ParentCluster(cluster logicalcluster.Name) (logicalcluster.Name, bool) {
+	segments, err := Segments(cluster)
+	if err != nil || len(segments) < 2 {
+		return logicalcluster.Name{}, false
+	}
+	return logicalcluster.New(strings.Join(segments[:len(segments)-1], ":")), true
+}
+
+// IsDirectChild indicates whether child is exactly one level below parent,
+// e.g. root:a is a direct child of root, but root:a:b is not.
+func IsDirectChild(parent, child logicalcluster.Name) bool {
+	actual, ok := ParentCluster(child)
+	return ok && actual == parent
+}
+
+// MaxClusterMetricLabelLength bounds the length of ClusterMetricLabel values.
+const MaxClusterMetricLabelLength = 128
+
+// ClusterMetricLabel returns a representation of cluster that is safe as a
+// Prometheus label value. Characters other than lowercase letters, digits,
+// "-" and ":" are replaced by "_", and names longer than
+// MaxClusterMetricLabelLength are truncated and suffixed with a hash of the
+// full name. The mapping is deterministic but lossy, so labels cannot be
+// turned back into cluster names. Valid clusters of ordinary length are
+// returned unchanged.
+func ClusterMetricLabel(cluster logicalcluster.Name) string {
+	label := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, cluster.String())
+	if len(label) <= MaxClusterMetricLabelLength {
+		return label
+	}
+	sum := sha256.Sum256([]byte(cluster.String()))
+	suffix := "-" + hex.EncodeToString(sum[:4])
+	return label[:MaxClusterMetricLabelLength-len(suffix)] + suffix
+}
+
+// ClusterHeader is the request header an authenticating proxy sets to the
+// logical cluster it resolved for a request.
+const ClusterHeader = "X-KCP-Cluster"
+
+// ClusterFromHeader parses the value of ClusterHeader, validating it like
+// clusters parsed from URLs. Surrounding whitespace is ignored.
+func ClusterFromHeader(value string) (logicalcluster.Name, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return logicalcluster.Name{}, fmt.Errorf("%s header must not be empty", ClusterHeader)
+	}
+	cluster := logicalcluster.New(value)
+	if !IsValidCluster(cluster) {
+		return logicalcluster.Name{}, fmt.Errorf("%s header %q is not a valid cluster", ClusterHeader, value)
+	}
+	return cluster, nil
+}
+
+// AllSameCluster returns the cluster all objs live in, as returned by
+// ClusterFromObject. It fails if objs is empty, if an object has a missing or
+// invalid cluster annotation, or if an object lives in another cluster than
+// the first one, naming the offending object.
+func AllSameCluster(objs []metav1.Object) (logicalcluster.Name, error) {
+	if len(objs) == 0 {
+		return logicalcluster.Name{}, fmt.Errorf("no objects")
+	}
+	first, err := ClusterFromObject(objs[0])
+	if err != nil {
+		return logicalcluster.Name{}, err
+	}
+	for _, obj := range objs[1:] {
+		cluster, err := ClusterFromObject(obj)
+		if err != nil {
+			return logicalcluster.Name{}, err
+		}
+		if cluster != first {
+			return logicalcluster.Name{}, fmt.Errorf("%s is not in cluster %q of %s", HumanRef(obj), first, HumanRef(objs[0]))
+		}
+	}
+	return first, nil
+}
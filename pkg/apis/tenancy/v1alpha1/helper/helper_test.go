@@ -17,11 +17,22 @@ limitations under the License.
 package helper
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
 )
 
 func TestIsValidCluster(t *testing.T) {
@@ -68,6 +79,10 @@ func TestIsValidCluster(t *testing.T) {
 	}
 }
 
+func TestRootClusters(t *testing.T) {
+	require.ElementsMatch(t, []logicalcluster.Name{logicalcluster.New("root"), logicalcluster.New("system")}, RootClusters())
+}
+
 func TestQualifiedObjectName(t *testing.T) {
 	tests := []struct {
 		obj  metav1.Object
@@ -96,6 +111,83 @@ func TestQualifiedObjectName(t *testing.T) {
 	}
 }
 
+func TestHumanRef(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cm",
+			Namespace:   "ns",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:acme:prod"},
+		},
+	}
+	ns := &corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ns",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:acme:prod"},
+		},
+	}
+	noCluster := &metav1.ObjectMeta{Name: "cm", Namespace: "ns"}
+
+	require.Equal(t, `workspace "root:acme:prod": configmap "ns/cm"`, HumanRef(cm))
+	require.Equal(t, `workspace "root:acme:prod": namespace "ns"`, HumanRef(ns))
+	require.Equal(t, `object "ns/cm"`, HumanRef(noCluster))
+
+	require.Equal(t, `workspace "root:acme:prod": configmaps "ns/cm"`, HumanRefForGVR(corev1.SchemeGroupVersion.WithResource("configmaps"), cm))
+	require.Equal(t, `workspace "root:acme:prod": deployments.apps "ns/cm"`, HumanRefForGVR(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, &metav1.ObjectMeta{
+		Name:        "cm",
+		Namespace:   "ns",
+		Annotations: map[string]string{logicalcluster.AnnotationKey: "root:acme:prod"},
+	}))
+	require.Equal(t, `configmaps "ns/cm"`, HumanRefForGVR(corev1.SchemeGroupVersion.WithResource("configmaps"), noCluster))
+}
+
+func TestIsNamespaced(t *testing.T) {
+	tests := []struct {
+		obj        metav1.Object
+		namespaced bool
+	}{
+		{&metav1.ObjectMeta{Name: "cool-name"}, false},
+		{&metav1.ObjectMeta{Name: "cool-name", Namespace: "cool-namespace"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.obj.GetNamespace()+"/"+tt.obj.GetName(), func(t *testing.T) {
+			if got := IsNamespaced(tt.obj); got != tt.namespaced {
+				t.Errorf("IsNamespaced(%v) = %v, want %v", tt.obj, got, tt.namespaced)
+			}
+		})
+	}
+}
+
+func TestSameNamespacedName(t *testing.T) {
+	obj := func(cluster, namespace, name string) metav1.Object {
+		return &metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{logicalcluster.AnnotationKey: cluster},
+		}
+	}
+	tests := []struct {
+		name string
+		a, b metav1.Object
+		same bool
+	}{
+		{"same name in different clusters", obj("root:a", "ns", "cm"), obj("root:b", "ns", "cm"), true},
+		{"same name in same cluster", obj("root:a", "ns", "cm"), obj("root:a", "ns", "cm"), true},
+		{"different namespaces", obj("root:a", "ns1", "cm"), obj("root:a", "ns2", "cm"), false},
+		{"different names", obj("root:a", "ns", "cm1"), obj("root:b", "ns", "cm2"), false},
+		{"cluster-scoped in different clusters", obj("root:a", "", "crb"), obj("root:b", "", "crb"), true},
+		{"cluster-scoped vs namespaced", obj("root:a", "", "cm"), obj("root:a", "ns", "cm"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameNamespacedName(tt.a, tt.b); got != tt.same {
+				t.Errorf("SameNamespacedName() = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
 func TestWorkspaceLabelSelector(t *testing.T) {
 	tests := []struct {
 		ws       string
@@ -111,3 +203,1475 @@ func TestWorkspaceLabelSelector(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkspaceNamesInSelector(t *testing.T) {
+	tests := []struct {
+		names    []string
+		selector string
+		wantErr  bool
+	}{
+		{names: []string{"cool-ws"}, selector: "workspaces.kcp.dev/name in (cool-ws)"},
+		{names: []string{"c", "a", "b", "a"}, selector: "workspaces.kcp.dev/name in (a,b,c)"},
+		{names: nil, wantErr: true},
+		{names: []string{"not valid"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.names, ","), func(t *testing.T) {
+			got, err := WorkspaceNamesInSelector(tt.names...)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.selector, got.String())
+			for _, name := range tt.names {
+				require.True(t, got.Matches(WorkspaceNameLabels(name)))
+			}
+			require.False(t, got.Matches(WorkspaceNameLabels("other")))
+		})
+	}
+}
+
+func TestClusterFieldSelector(t *testing.T) {
+	selector, err := ClusterFieldSelector(logicalcluster.New("root:foo"))
+	require.NoError(t, err)
+	require.Equal(t, "spec.cluster=root:foo", selector.String())
+	require.True(t, selector.Matches(fields.Set{ClusterFieldName: "root:foo"}))
+	require.False(t, selector.Matches(fields.Set{ClusterFieldName: "root:bar"}))
+
+	_, err = ClusterFieldSelector(logicalcluster.New("foo:bar"))
+	require.Error(t, err)
+}
+
+func TestValidateWorkspaceSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		wantErr  bool
+	}{
+		{selector: ""},
+		{selector: "workspaces.kcp.dev/name=a"},
+		{selector: "workspaces.kcp.dev/name=a,app=web"},
+		{selector: "workspaces.kcp.dev/name in (a,b),workspaces.kcp.dev/name=b"},
+		{selector: "workspaces.kcp.dev/name in (a,b),workspaces.kcp.dev/name!=a"},
+		{selector: "workspaces.kcp.dev/name,workspaces.kcp.dev/name notin (a)"},
+		{selector: "app=a,app=b"}, // only the workspace label is checked
+
+		{selector: "workspaces.kcp.dev/name=a,workspaces.kcp.dev/name=b", wantErr: true},
+		{selector: "workspaces.kcp.dev/name in (a,b),workspaces.kcp.dev/name in (c)", wantErr: true},
+		{selector: "workspaces.kcp.dev/name=a,workspaces.kcp.dev/name!=a", wantErr: true},
+		{selector: "workspaces.kcp.dev/name in (a,b),workspaces.kcp.dev/name notin (a,b)", wantErr: true},
+		{selector: "workspaces.kcp.dev/name=a,!workspaces.kcp.dev/name", wantErr: true},
+		{selector: "workspaces.kcp.dev/name,!workspaces.kcp.dev/name", wantErr: true},
+
+		{selector: "workspaces.kcp.dev/name in (a", wantErr: true},
+		{selector: "Not A Selector!", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			err := ValidateWorkspaceSelector(tt.selector)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWorkspaceNameLabels(t *testing.T) {
+	tests := []string{"cool-ws", "Cool.WS_1"}
+	for _, ws := range tests {
+		t.Run(ws, func(t *testing.T) {
+			got := WorkspaceNameLabels(ws)
+			require.Equal(t, labels.Set{v1beta1.WorkspaceNameLabel: ws}, got)
+			require.True(t, labels.SelectorFromSet(got).Matches(got))
+			selector, err := labels.Parse(WorkspaceLabelSelector(ws))
+			require.NoError(t, err)
+			require.True(t, selector.Matches(got), "WorkspaceLabelSelector(%q) does not match WorkspaceNameLabels(%q)", ws, ws)
+		})
+	}
+}
+
+func TestMergeWorkspaceLabels(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		require.Equal(t, map[string]string{v1beta1.WorkspaceNameLabel: "ws"}, MergeWorkspaceLabels(nil, "ws"))
+	})
+
+	t.Run("conflicting and unrelated labels", func(t *testing.T) {
+		existing := map[string]string{
+			v1beta1.WorkspaceNameLabel: "old",
+			"app":                      "web",
+		}
+		got := MergeWorkspaceLabels(existing, "new")
+		require.Equal(t, map[string]string{
+			v1beta1.WorkspaceNameLabel: "new",
+			"app":                      "web",
+		}, got)
+		require.Equal(t, map[string]string{
+			v1beta1.WorkspaceNameLabel: "old",
+			"app":                      "web",
+		}, existing, "existing labels must not be mutated")
+	})
+}
+
+func TestTruncateCluster(t *testing.T) {
+	tests := []struct {
+		cluster string
+		depth   int
+		want    string
+	}{
+		{"root:org:team:proj", 0, "root"},
+		{"root:org:team:proj", 1, "root:org"},
+		{"root:org:team:proj", 2, "root:org:team"},
+		{"root:org:team:proj", 3, "root:org:team:proj"},
+		{"root:org:team:proj", 10, "root:org:team:proj"},
+		{"root", 0, "root"},
+		{"root", 2, "root"},
+		{"system:foo:bar", 1, "system:foo"},
+
+		{"root:org", -1, ""},
+		{"", 1, ""},
+		{"foo:bar", 1, ""},
+		{"root::foo", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.cluster, tt.depth), func(t *testing.T) {
+			if got := TruncateCluster(logicalcluster.New(tt.cluster), tt.depth); got != logicalcluster.New(tt.want) {
+				t.Errorf("TruncateCluster(%q, %d) = %q, want %q", tt.cluster, tt.depth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualifiedObjectNameWithShard(t *testing.T) {
+	tests := []struct {
+		shard     string
+		obj       metav1.Object
+		qualified string
+	}{
+		{"", &metav1.ObjectMeta{
+			Name:        "cool-name",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:foo"},
+		}, "root:foo|cool-name"},
+		{"", &metav1.ObjectMeta{
+			Name:        "cool-name",
+			Namespace:   "cool-namespace",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:foo"},
+		}, "root:foo|cool-namespace/cool-name"},
+		{"shard-1", &metav1.ObjectMeta{
+			Name:        "cool-name",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:foo"},
+		}, "shard-1/root:foo|cool-name"},
+		{"shard-1", &metav1.ObjectMeta{
+			Name:        "cool-name",
+			Namespace:   "cool-namespace",
+			Annotations: map[string]string{logicalcluster.AnnotationKey: "root:foo"},
+		}, "shard-1/root:foo|cool-namespace/cool-name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.qualified, func(t *testing.T) {
+			got := QualifiedObjectNameWithShard(tt.shard, tt.obj)
+			require.Equal(t, tt.qualified, got)
+
+			shard, cluster, namespace, name, err := ParseQualifiedObjectNameWithShard(got)
+			require.NoError(t, err)
+			require.Equal(t, tt.shard, shard)
+			require.Equal(t, logicalcluster.From(tt.obj), cluster)
+			require.Equal(t, tt.obj.GetNamespace(), namespace)
+			require.Equal(t, tt.obj.GetName(), name)
+		})
+	}
+}
+
+func TestParseQualifiedObjectNameWithShardMalformed(t *testing.T) {
+	for _, qualified := range []string{
+		"",
+		"root:foo",
+		"shard-1/root:foo",
+		"/root:foo|name",
+		"shard-1/root:foo|",
+		"shard-1/root:foo|ns/",
+		"shard-1/root:foo|/name",
+		"shard-1/root:foo|ns/name/extra",
+		"root:foo|ns/name|extra",
+	} {
+		t.Run(qualified, func(t *testing.T) {
+			_, _, _, _, err := ParseQualifiedObjectNameWithShard(qualified)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidateClusterLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		cluster    string
+		maxSegment int
+		maxTotal   int
+		wantErr    string
+	}{
+		{name: "below limits", cluster: "root:abc:de", maxSegment: 4, maxTotal: 20},
+		{name: "segment at limit", cluster: "root:abcd", maxSegment: 4, maxTotal: 20},
+		{name: "total at limit", cluster: "root:abcd", maxSegment: 4, maxTotal: 9},
+		{name: "segment beyond limit", cluster: "root:abcde:x", maxSegment: 4, maxTotal: 20, wantErr: `segment 1 "abcde"`},
+		{name: "root segment beyond limit", cluster: "system:a", maxSegment: 4, maxTotal: 20, wantErr: `segment 0 "system"`},
+		{name: "total beyond limit", cluster: "root:abcd:e", maxSegment: 4, maxTotal: 10, wantErr: "maximum length of 10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateClusterLength(logicalcluster.New(tt.cluster), tt.maxSegment, tt.maxTotal)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestWalkAncestors(t *testing.T) {
+	t.Run("order", func(t *testing.T) {
+		var visited []string
+		err := WalkAncestors(logicalcluster.New("root:a:b:c"), func(c logicalcluster.Name) (bool, error) {
+			visited = append(visited, c.String())
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"root:a:b:c", "root:a:b", "root:a", "root"}, visited)
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		var visited []string
+		err := WalkAncestors(logicalcluster.New("root:a:b:c"), func(c logicalcluster.Name) (bool, error) {
+			visited = append(visited, c.String())
+			return c.String() == "root:a:b", nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"root:a:b:c", "root:a:b"}, visited)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		boom := errors.New("boom")
+		var visited []string
+		err := WalkAncestors(logicalcluster.New("root:a:b"), func(c logicalcluster.Name) (bool, error) {
+			visited = append(visited, c.String())
+			if c.String() == "root:a" {
+				return false, boom
+			}
+			return false, nil
+		})
+		require.ErrorIs(t, err, boom)
+		require.Equal(t, []string{"root:a:b", "root:a"}, visited)
+	})
+
+	t.Run("invalid cluster", func(t *testing.T) {
+		err := WalkAncestors(logicalcluster.New("foo:bar"), func(c logicalcluster.Name) (bool, error) {
+			t.Fatalf("unexpected call for %q", c)
+			return false, nil
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestDivergencePoint(t *testing.T) {
+	tests := []struct {
+		a, b       string
+		common     string
+		aRemainder []string
+		bRemainder []string
+	}{
+		{a: "root:a:b", b: "root:a:b", common: "root:a:b"},
+		{a: "root:a", b: "root:a:b:c", common: "root:a", bRemainder: []string{"b", "c"}},
+		{a: "root:a:b:c", b: "root:a", common: "root:a", aRemainder: []string{"b", "c"}},
+		{a: "root:a:x", b: "root:a:y:z", common: "root:a", aRemainder: []string{"x"}, bRemainder: []string{"y", "z"}},
+		{a: "root:acme:x", b: "root:acme2:x", common: "root", aRemainder: []string{"acme", "x"}, bRemainder: []string{"acme2", "x"}},
+		{a: "root:foo", b: "system:foo", common: "", aRemainder: []string{"root", "foo"}, bRemainder: []string{"system", "foo"}},
+		{a: "", b: "root", common: "", bRemainder: []string{"root"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			common, aRemainder, bRemainder := DivergencePoint(logicalcluster.New(tt.a), logicalcluster.New(tt.b))
+			require.Equal(t, logicalcluster.New(tt.common), common)
+			require.Equal(t, strings.Join(tt.aRemainder, ":"), strings.Join(aRemainder, ":"))
+			require.Equal(t, strings.Join(tt.bRemainder, ":"), strings.Join(bRemainder, ":"))
+		})
+	}
+}
+
+func TestIsRoutingSafeWorkspaceName(t *testing.T) {
+	tests := []struct {
+		name string
+		safe bool
+	}{
+		{"foo", true},
+		{"my-clusters", true},
+		{"clusters2", true},
+
+		{"clusters", false},
+		{"services", false},
+		{"workspaces", false},
+		{"shards", false},
+
+		{"", false},
+		{"*", false},
+		{"root:foo", false},
+		{"Foo", false},
+		{"0foo", false},
+		{"foo-", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRoutingSafeWorkspaceName(tt.name); got != tt.safe {
+				t.Errorf("IsRoutingSafeWorkspaceName(%q) = %v, want %v", tt.name, got, tt.safe)
+			}
+		})
+	}
+}
+
+func TestParseClusterList(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr []string
+	}{
+		{input: ""},
+		{input: "   "},
+		{input: "root:a", want: []string{"root:a"}},
+		{input: "root:a,root:b:c", want: []string{"root:a", "root:b:c"}},
+		{input: " root:a ,\troot:b:c ", want: []string{"root:a", "root:b:c"}},
+		{input: "root:a,root:a", wantErr: []string{`duplicate cluster "root:a"`}},
+		{input: "root:a,foo,root:b,root:a,", wantErr: []string{`invalid cluster "foo"`, `duplicate cluster "root:a"`, `invalid cluster ""`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseClusterList(tt.input)
+			if len(tt.wantErr) > 0 {
+				require.Error(t, err)
+				for _, want := range tt.wantErr {
+					require.Contains(t, err.Error(), want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			var want []logicalcluster.Name
+			for _, c := range tt.want {
+				want = append(want, logicalcluster.New(c))
+			}
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestInOrganization(t *testing.T) {
+	tests := []struct {
+		cluster, org string
+		want         bool
+	}{
+		{"root:acme", "root:acme", true},
+		{"root:acme:team", "root:acme", true},
+		{"root:acme:team:proj", "root:acme", true},
+		{"root:acme2", "root:acme", false},
+		{"root:acme2:team", "root:acme", false},
+		{"root:acme", "root:acme:team", false},
+		{"root:other", "root:acme", false},
+		{"system:acme", "root:acme", false},
+		{"root:acme", "system:acme", false},
+		{"root:acme", "root", true},
+		{"", "root:acme", false},
+		{"root:acme", "", false},
+		{"foo:acme", "foo", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster+"/"+tt.org, func(t *testing.T) {
+			if got := InOrganization(logicalcluster.New(tt.cluster), logicalcluster.New(tt.org)); got != tt.want {
+				t.Errorf("InOrganization(%q, %q) = %v, want %v", tt.cluster, tt.org, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRebaseCluster(t *testing.T) {
+	tests := []struct {
+		cluster string
+		newRoot string
+		want    string
+		wantErr bool
+	}{
+		{cluster: "system", newRoot: "root", want: "root"},
+		{cluster: "system:foo", newRoot: "root", want: "root:foo"},
+		{cluster: "system:foo:bar", newRoot: "root", want: "root:foo:bar"},
+		{cluster: "system:foo:bar:baz", newRoot: "root", want: "root:foo:bar:baz"},
+		{cluster: "root:foo:bar", newRoot: "system", want: "system:foo:bar"},
+		{cluster: "system:foo", newRoot: "root:mirror", want: "root:mirror:foo"},
+
+		{cluster: "", newRoot: "root", wantErr: true},
+		{cluster: "foo:bar", newRoot: "root", wantErr: true},
+		{cluster: "system::foo", newRoot: "root", wantErr: true},
+		{cluster: "system:foo", newRoot: "other", wantErr: true},
+		{cluster: "system:foo", newRoot: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster+"/"+tt.newRoot, func(t *testing.T) {
+			got, err := RebaseCluster(logicalcluster.New(tt.cluster), logicalcluster.New(tt.newRoot))
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestLeafName(t *testing.T) {
+	tests := []struct {
+		cluster string
+		leaf    string
+		wantErr bool
+	}{
+		{cluster: "root:acme", leaf: "acme"},
+		{cluster: "root:acme:platform:prod", leaf: "prod"},
+		{cluster: "system:foo", leaf: "foo"},
+		{cluster: "root", wantErr: true},
+		{cluster: "system", wantErr: true},
+		{cluster: "", wantErr: true},
+		{cluster: "foo:bar", wantErr: true},
+		{cluster: "root:acme:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			got, err := LeafName(logicalcluster.New(tt.cluster))
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.leaf, got)
+		})
+	}
+}
+
+func TestCleanAndValidateCluster(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "root:foo", want: "root:foo"},
+		{raw: "  root:foo\t", want: "root:foo"},
+		{raw: "root:foo:", want: "root:foo"},
+		{raw: " root:foo: ", want: "root:foo"},
+		{raw: "system", want: "system"},
+
+		{raw: "", wantErr: true},
+		{raw: "   ", wantErr: true},
+		{raw: ":", wantErr: true},
+		{raw: "root:foo::", wantErr: true},
+		{raw: "root::foo", wantErr: true},
+		{raw: "root: foo", wantErr: true},
+		{raw: "root:fo o", wantErr: true},
+		{raw: "foo:bar", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := CleanAndValidateCluster(tt.raw)
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestExpandHomeShorthand(t *testing.T) {
+	home := logicalcluster.New("root:users:ab:cd:user")
+	tests := []struct {
+		raw       string
+		shorthand bool
+		want      string
+		wantErr   bool
+	}{
+		{raw: "~", shorthand: true, want: "root:users:ab:cd:user"},
+		{raw: "~:projects", shorthand: true, want: "root:users:ab:cd:user:projects"},
+		{raw: "~:projects:foo", shorthand: true, want: "root:users:ab:cd:user:projects:foo"},
+		{raw: "root:foo", shorthand: false, want: "root:foo"},
+		{raw: "~foo", shorthand: false, wantErr: true},
+		{raw: "root:~", shorthand: false, wantErr: true},
+		{raw: "~:", shorthand: true, wantErr: true},
+		{raw: "~:Projects", shorthand: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			require.Equal(t, tt.shorthand, HasHomeShorthand(tt.raw))
+
+			got, err := ExpandHomeShorthand(tt.raw, home)
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+
+	_, err := ExpandHomeShorthand("~:projects", logicalcluster.Name{})
+	require.Error(t, err, "expected error for an empty home workspace")
+}
+
+func TestClusterGVRKey(t *testing.T) {
+	tests := []struct {
+		cluster string
+		gvr     schema.GroupVersionResource
+		key     string
+	}{
+		{"root:foo", schema.GroupVersionResource{Version: "v1", Resource: "pods"}, "root:foo|/v1/pods"},
+		{"root:foo", schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, "root:foo|apps/v1/deployments"},
+		{"system:bar", schema.GroupVersionResource{Group: "tenancy.kcp.dev", Version: "v1alpha1", Resource: "clusterworkspaces"}, "system:bar|tenancy.kcp.dev/v1alpha1/clusterworkspaces"},
+		{"*", schema.GroupVersionResource{Version: "v1", Resource: "pods/status"}, "*|/v1/pods/status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			key := ClusterGVRKey(logicalcluster.New(tt.cluster), tt.gvr)
+			require.Equal(t, tt.key, key)
+
+			cluster, gvr, err := ParseClusterGVRKey(key)
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.cluster), cluster)
+			require.Equal(t, tt.gvr, gvr)
+		})
+	}
+
+	for _, key := range []string{"", "root:foo", "root:foo|v1/pods", "root:foo|apps//deployments", "root:foo|apps/v1/"} {
+		t.Run(key, func(t *testing.T) {
+			_, _, err := ParseClusterGVRKey(key)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidateMove(t *testing.T) {
+	tests := []struct {
+		source, newParent string
+		want              string
+		wantErr           bool
+	}{
+		{source: "root:a:proj", newParent: "root:b", want: "root:b:proj"},
+		{source: "root:a:proj", newParent: "root", want: "root:proj"},
+		{source: "root:a:proj", newParent: "root:b:c:d", want: "root:b:c:d:proj"},
+		{source: "root:a:proj", newParent: "root:a:proj2", want: "root:a:proj2:proj"},
+		{source: "system:a:proj", newParent: "system:b", want: "system:b:proj"},
+
+		// cycles
+		{source: "root:a", newParent: "root:a", wantErr: true},
+		{source: "root:a", newParent: "root:a:b", wantErr: true},
+		{source: "root:a", newParent: "root:a:b:c", wantErr: true},
+
+		// cross-root
+		{source: "root:a:proj", newParent: "system:b", wantErr: true},
+		{source: "system:a:proj", newParent: "root:b", wantErr: true},
+
+		// invalid
+		{source: "root", newParent: "root:b", wantErr: true},
+		{source: "root:a", newParent: "foo", wantErr: true},
+		{source: "foo:a", newParent: "root:b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source+"->"+tt.newParent, func(t *testing.T) {
+			got, err := ValidateMove(logicalcluster.New(tt.source), logicalcluster.New(tt.newParent))
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestClustersFromObjects(t *testing.T) {
+	obj := func(name, cluster string) metav1.Object {
+		o := &metav1.ObjectMeta{Name: name}
+		if cluster != "" {
+			o.Annotations = map[string]string{logicalcluster.AnnotationKey: cluster}
+		}
+		return o
+	}
+	a1, a2, b := obj("a1", "root:a"), obj("a2", "root:a"), obj("b", "root:b")
+	missing, empty, invalid := obj("missing", ""), &metav1.ObjectMeta{Name: "empty", Annotations: map[string]string{logicalcluster.AnnotationKey: ""}}, obj("invalid", "foo:bar")
+
+	byCluster, bad := ClustersFromObjects([]metav1.Object{a1, missing, b, invalid, a2, empty})
+	require.Equal(t, map[logicalcluster.Name][]metav1.Object{
+		logicalcluster.New("root:a"): {a1, a2},
+		logicalcluster.New("root:b"): {b},
+	}, byCluster)
+	require.Equal(t, []metav1.Object{missing, invalid, empty}, bad)
+
+	byCluster, bad = ClustersFromObjects(nil)
+	require.Empty(t, byCluster)
+	require.Empty(t, bad)
+}
+
+func TestTierOf(t *testing.T) {
+	tests := []struct {
+		cluster string
+		tier    ClusterTier
+		wantErr bool
+	}{
+		{cluster: "root:acme", tier: OrganizationTier},
+		{cluster: "system:foo", tier: OrganizationTier},
+		{cluster: "root:acme:web", tier: TeamTier},
+		{cluster: "root:acme:web:prod", tier: ProjectTier},
+		{cluster: "root:acme:web:prod:eu", tier: ProjectTier},
+		{cluster: "root", wantErr: true},
+		{cluster: "system", wantErr: true},
+		{cluster: "foo:bar", wantErr: true},
+		{cluster: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			got, err := TierOf(logicalcluster.New(tt.cluster))
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.tier, got)
+		})
+	}
+}
+
+func TestClusterShardIndex(t *testing.T) {
+	_, err := ClusterShardIndex(logicalcluster.New("root:foo"), 0)
+	require.Error(t, err)
+	_, err = ClusterShardIndex(logicalcluster.New("root:foo"), -1)
+	require.Error(t, err)
+
+	// the values are pinned to catch accidental changes of the hash, which
+	// would reshuffle all assignments
+	for cluster, want := range map[string]int{
+		"root":         173,
+		"root:foo":     511,
+		"root:foo:bar": 240,
+	} {
+		for i := 0; i < 3; i++ {
+			got, err := ClusterShardIndex(logicalcluster.New(cluster), 1000)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		}
+	}
+
+	counts := make([]int, 4)
+	for i := 0; i < 400; i++ {
+		index, err := ClusterShardIndex(logicalcluster.New(fmt.Sprintf("root:org-%d", i)), len(counts))
+		require.NoError(t, err)
+		require.True(t, index >= 0 && index < len(counts))
+		counts[index]++
+	}
+	for index, count := range counts {
+		require.Greater(t, count, 50, "shard %d got too few clusters: %v", index, counts)
+	}
+}
+
+func TestValidateNewChild(t *testing.T) {
+	existing := []string{"a", "b"}
+	require.False(t, IsUniqueSibling("a", existing))
+	require.True(t, IsUniqueSibling("c", existing))
+	require.True(t, IsUniqueSibling("a", nil))
+
+	tests := []struct {
+		parent  string
+		name    string
+		wantErr string
+	}{
+		{parent: "root:org", name: "c"},
+		{parent: "root:org", name: "a", wantErr: "already exists"},
+		{parent: "root:org", name: "Bad_Name", wantErr: "invalid workspace name"},
+		{parent: "root:org", name: "x:y", wantErr: "invalid workspace name"},
+		{parent: "root:org", name: "clusters", wantErr: "reserved"},
+		{parent: "foo", name: "c", wantErr: "invalid parent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.parent+"/"+tt.name, func(t *testing.T) {
+			err := ValidateNewChild(logicalcluster.New(tt.parent), tt.name, existing)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestNextSegmentUnder(t *testing.T) {
+	tests := []struct {
+		parent, descendant string
+		want               string
+		ok                 bool
+	}{
+		{parent: "root:org", descendant: "root:org:team", want: "team", ok: true},
+		{parent: "root:org", descendant: "root:org:team:proj", want: "team", ok: true},
+		{parent: "root", descendant: "root:org:team", want: "org", ok: true},
+		{parent: "root:org", descendant: "root:other:team", ok: false},
+		{parent: "root:org", descendant: "root:org2:team", ok: false},
+		{parent: "root:org", descendant: "root:org", ok: false},
+		{parent: "root:org:team", descendant: "root:org", ok: false},
+		{parent: "", descendant: "root:org", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.parent+"/"+tt.descendant, func(t *testing.T) {
+			got, ok := NextSegmentUnder(logicalcluster.New(tt.parent), logicalcluster.New(tt.descendant))
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClusterFromSegments(t *testing.T) {
+	tests := []struct {
+		root     string
+		segments []string
+		want     string
+		wantErr  string
+	}{
+		{root: "root", segments: []string{"org", "team"}, want: "root:org:team"},
+		{root: "root:org", segments: []string{"team"}, want: "root:org:team"},
+		{root: "system", segments: nil, want: "system"},
+		{root: "root", segments: []string{"org", "", "team"}, wantErr: `segment 1 ""`},
+		{root: "root", segments: []string{"org", "te_am"}, wantErr: `segment 1 "te_am"`},
+		{root: "root", segments: []string{"a:b"}, wantErr: `segment 0 "a:b"`},
+		{root: "foo", segments: []string{"org"}, wantErr: "invalid root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.root+":"+strings.Join(tt.segments, ":"), func(t *testing.T) {
+			got, err := ClusterFromSegments(logicalcluster.New(tt.root), tt.segments...)
+			if tt.wantErr != "" {
+				require.Error(t, err, "instead of error got %q", got)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestRedactCluster(t *testing.T) {
+	redacted := RedactCluster(logicalcluster.New("root:acme:prod"))
+	require.Regexp(t, `^root:\*\*\*[0-9a-f]{4}:\*\*\*[0-9a-f]{4}$`, redacted)
+	require.NotContains(t, redacted, "acme")
+	require.NotContains(t, redacted, "prod")
+	require.Equal(t, redacted, RedactCluster(logicalcluster.New("root:acme:prod")), "redaction must be deterministic")
+
+	require.Equal(t, "root", RedactCluster(logicalcluster.New("root")))
+	require.Regexp(t, `^system:\*\*\*[0-9a-f]{4}$`, RedactCluster(logicalcluster.New("system:acme")))
+
+	// equal leaf names in different parents redact differently, shared
+	// ancestors identically
+	other := RedactCluster(logicalcluster.New("root:other:prod"))
+	require.NotEqual(t, redacted, other)
+	require.Equal(t, strings.Split(redacted, ":")[1], strings.Split(RedactCluster(logicalcluster.New("root:acme:dev")), ":")[1])
+
+	require.NotContains(t, RedactCluster(logicalcluster.New("acme:prod")), "acme")
+
+	obj := &metav1.ObjectMeta{
+		Name:        "cm",
+		Namespace:   "ns",
+		Annotations: map[string]string{logicalcluster.AnnotationKey: "root:acme:prod"},
+	}
+	require.Equal(t, redacted+"|ns/cm", RedactQualifiedObjectName(obj))
+	obj.Namespace = ""
+	require.Equal(t, redacted+"|cm", RedactQualifiedObjectName(obj))
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+		wantErr  bool
+	}{
+		{from: "root:a:b", to: "root:a:c", want: "../c"},
+		{from: "root:a:b", to: "root:a:b", want: "."},
+		{from: "root:a:b:c", to: "root:a", want: "../.."},
+		{from: "root", to: "root:a:b", want: "a/b"},
+		{from: "root:a:b", to: "root:x:y", want: "../../x/y"},
+		{from: "root:a", to: "system:a", wantErr: true},
+		{from: "root:a", to: "", wantErr: true},
+		{from: "abc", to: "root", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			got, err := RelativePath(logicalcluster.New(tt.from), logicalcluster.New(tt.to))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWouldExceedSegmentLimit(t *testing.T) {
+	atLimit := strings.Repeat("a", MaxWorkspaceSegmentLength)
+	aboveLimit := strings.Repeat("b", MaxWorkspaceSegmentLength+1)
+
+	tests := []struct {
+		name        string
+		cluster     string
+		wantSegment string
+		wantExceeds bool
+	}{
+		{name: "below", cluster: "root:foo:bar"},
+		{name: "at", cluster: "root:" + atLimit},
+		{name: "above", cluster: "root:" + aboveLimit, wantSegment: aboveLimit, wantExceeds: true},
+		{name: "first of several", cluster: "root:" + atLimit + ":" + aboveLimit + ":c" + aboveLimit, wantSegment: aboveLimit, wantExceeds: true},
+		{name: "empty", cluster: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segment, exceeds := WouldExceedSegmentLimit(logicalcluster.New(tt.cluster))
+			require.Equal(t, tt.wantExceeds, exceeds)
+			require.Equal(t, tt.wantSegment, segment)
+		})
+	}
+}
+
+func TestWorkspaceLabelMatchesCluster(t *testing.T) {
+	tests := []struct {
+		name        string
+		cluster     string
+		labels      map[string]string
+		wantMatches bool
+		wantErr     bool
+	}{
+		{name: "matching", cluster: "root:acme:prod", labels: map[string]string{v1beta1.WorkspaceNameLabel: "prod"}, wantMatches: true},
+		{name: "mismatching", cluster: "root:acme:prod", labels: map[string]string{v1beta1.WorkspaceNameLabel: "dev"}},
+		{name: "label of the parent", cluster: "root:acme:prod", labels: map[string]string{v1beta1.WorkspaceNameLabel: "acme"}},
+		{name: "missing label", cluster: "root:acme:prod", labels: map[string]string{"foo": "bar"}, wantErr: true},
+		{name: "missing cluster", labels: map[string]string{v1beta1.WorkspaceNameLabel: "prod"}, wantErr: true},
+		{name: "root cluster", cluster: "root", labels: map[string]string{v1beta1.WorkspaceNameLabel: "root"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Name: "cm", Labels: tt.labels}
+			if tt.cluster != "" {
+				obj.Annotations = map[string]string{logicalcluster.AnnotationKey: tt.cluster}
+			}
+			matches, err := WorkspaceLabelMatchesCluster(obj)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMatches, matches)
+		})
+	}
+}
+
+func TestAncestorsOf(t *testing.T) {
+	ancestors, err := AncestorsOf(logicalcluster.New("root:a:b:c"))
+	require.NoError(t, err)
+	require.Equal(t, []logicalcluster.Name{logicalcluster.New("root:a:b"), logicalcluster.New("root:a"), logicalcluster.New("root")}, ancestors)
+
+	ancestors, err = AncestorsOf(logicalcluster.New("root"))
+	require.NoError(t, err)
+	require.Empty(t, ancestors)
+
+	_, err = AncestorsOf(logicalcluster.New("abc:def"))
+	require.Error(t, err)
+}
+
+func TestAncestorNameSelector(t *testing.T) {
+	tests := []struct {
+		cluster string
+		want    string
+		wantErr bool
+	}{
+		{cluster: "root:a:b:c:d", want: v1beta1.WorkspaceNameLabel + " in (a,b,c)"},
+		{cluster: "root:a:b", want: v1beta1.WorkspaceNameLabel + " in (a)"},
+		{cluster: "root:a:a", want: v1beta1.WorkspaceNameLabel + " in (a)"},
+		{cluster: "root:a"},
+		{cluster: "root"},
+		{cluster: "abc:def", wantErr: true},
+		{cluster: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			selector, err := AncestorNameSelector(logicalcluster.New(tt.cluster))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == "" {
+				require.False(t, selector.Matches(labels.Set{v1beta1.WorkspaceNameLabel: "root"}))
+				require.False(t, selector.Matches(labels.Set{v1beta1.WorkspaceNameLabel: "a"}))
+				return
+			}
+			require.Equal(t, tt.want, selector.String())
+		})
+	}
+}
+
+func TestResolveInherited(t *testing.T) {
+	values := map[logicalcluster.Name]string{
+		logicalcluster.New("root"):         "root-value",
+		logicalcluster.New("root:acme"):    "acme-value",
+		logicalcluster.New("root:acme:a"):  "",
+		logicalcluster.New("root:other:x"): "x-value",
+	}
+	tests := []struct {
+		cluster       string
+		values        map[logicalcluster.Name]string
+		wantValue     string
+		wantDefinedAt string
+		wantFound     bool
+	}{
+		{cluster: "root:acme", values: values, wantValue: "acme-value", wantDefinedAt: "root:acme", wantFound: true},
+		{cluster: "root:acme:b:c", values: values, wantValue: "acme-value", wantDefinedAt: "root:acme", wantFound: true},
+		{cluster: "root:acme:a:b", values: values, wantValue: "", wantDefinedAt: "root:acme:a", wantFound: true},
+		{cluster: "root:other", values: values, wantValue: "root-value", wantDefinedAt: "root", wantFound: true},
+		{cluster: "system:foo", values: values},
+		{cluster: "root:acme", values: nil},
+		{cluster: "abc:def", values: values},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			value, definedAt, found := ResolveInherited(logicalcluster.New(tt.cluster), tt.values)
+			require.Equal(t, tt.wantFound, found)
+			require.Equal(t, tt.wantValue, value)
+			require.Equal(t, logicalcluster.New(tt.wantDefinedAt), definedAt)
+		})
+	}
+}
+
+func TestIsReservedCluster(t *testing.T) {
+	require.True(t, IsReservedCluster(logicalcluster.New("root")))
+	require.True(t, IsReservedCluster(logicalcluster.New("system")))
+	require.True(t, IsReservedCluster(logicalcluster.New("system:foo")))
+	require.True(t, IsReservedCluster(logicalcluster.New("root:foo:system")))
+	require.True(t, IsReservedCluster(logicalcluster.New("root:root")))
+	require.False(t, IsReservedCluster(logicalcluster.New("root:foo")))
+	require.False(t, IsReservedCluster(logicalcluster.New("root:system-foo")))
+	require.False(t, IsReservedCluster(logicalcluster.New("root:system:foo")))
+}
+
+func TestValidateCreatableCluster(t *testing.T) {
+	tests := []struct {
+		cluster string
+		wantErr string
+	}{
+		{cluster: "root:foo:bar"},
+		{cluster: "root:foo-bar"},
+		{cluster: "abc:def", wantErr: "invalid"},
+		{cluster: "root:Foo", wantErr: "invalid"},
+		{cluster: "", wantErr: "invalid"},
+		{cluster: "root", wantErr: "reserved"},
+		{cluster: "system:foo", wantErr: "reserved"},
+		{cluster: "root:foo:root", wantErr: "reserved"},
+		{cluster: "root:clusters", wantErr: "routing"},
+		{cluster: "root:foo:services", wantErr: "routing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			err := ValidateCreatableCluster(logicalcluster.New(tt.cluster))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWorkQueueKey(t *testing.T) {
+	tests := []struct {
+		cluster, namespace, name string
+	}{
+		{cluster: "root:foo", namespace: "ns", name: "name"},
+		{cluster: "root:foo", name: "name"},
+		{cluster: "root:foo", namespace: "a|b/c", name: "d/e|f"},
+		{cluster: "root", namespace: "12:x", name: "3:abc"},
+		{name: "name"},
+		{},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster+"|"+tt.namespace+"/"+tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Namespace: tt.namespace, Name: tt.name}
+			if tt.cluster != "" {
+				obj.Annotations = map[string]string{logicalcluster.AnnotationKey: tt.cluster}
+			}
+			cluster, namespace, name, err := SplitWorkQueueKey(WorkQueueKey(obj))
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.cluster), cluster)
+			require.Equal(t, tt.namespace, namespace)
+			require.Equal(t, tt.name, name)
+		})
+	}
+
+	// names that collide in QualifiedObjectName must not collide here
+	a := &metav1.ObjectMeta{Namespace: "a", Name: "b/c", Annotations: map[string]string{logicalcluster.AnnotationKey: "root"}}
+	b := &metav1.ObjectMeta{Namespace: "a/b", Name: "c", Annotations: map[string]string{logicalcluster.AnnotationKey: "root"}}
+	require.Equal(t, QualifiedObjectName(a), QualifiedObjectName(b))
+	require.NotEqual(t, WorkQueueKey(a), WorkQueueKey(b))
+
+	for _, key := range []string{"", "root|ns/name", "8:root:foo", "8:root:foo2:ns", "8:root:foo2:ns4:name5:extra", "x:root0:0:", "-1:0:0:", "99:root0:0:"} {
+		_, _, _, err := SplitWorkQueueKey(key)
+		require.Error(t, err, key)
+	}
+}
+
+func TestClusterToDNSSubdomain(t *testing.T) {
+	long := "root"
+	for i := 0; i < 5; i++ {
+		long += ":" + strings.Repeat(string(rune('a'+i)), MaxWorkspaceSegmentLength)
+	}
+
+	tests := []struct {
+		cluster string
+		want    string
+		wantErr bool
+	}{
+		{cluster: "root", want: "root"},
+		{cluster: "root:acme:prod", want: "root.acme.prod"},
+		{cluster: "root:my-org:team-1", want: "root.my-org.team-1"},
+		{cluster: long, wantErr: true},
+		{cluster: "abc:def", wantErr: true},
+		{cluster: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			got, err := ClusterToDNSSubdomain(logicalcluster.New(tt.cluster))
+			require.Equal(t, !tt.wantErr, IsDNSSubdomainSafe(logicalcluster.New(tt.cluster)))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Empty(t, validation.IsDNS1123Subdomain(got))
+		})
+	}
+}
+
+func TestOrgAndWorkspace(t *testing.T) {
+	tests := []struct {
+		cluster       string
+		wantOrg       string
+		wantWorkspace string
+		wantErr       bool
+	}{
+		{cluster: "root:acme", wantOrg: "root:acme"},
+		{cluster: "root:acme:web", wantOrg: "root:acme", wantWorkspace: "web"},
+		{cluster: "root:acme:web:prod", wantOrg: "root:acme", wantWorkspace: "web:prod"},
+		{cluster: "system:foo:bar", wantOrg: "system:foo", wantWorkspace: "bar"},
+		{cluster: "root", wantErr: true},
+		{cluster: "abc:def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			org, workspace, err := OrgAndWorkspace(logicalcluster.New(tt.cluster))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.wantOrg), org)
+			require.Equal(t, tt.wantWorkspace, workspace)
+		})
+	}
+}
+
+func TestCommonOrganization(t *testing.T) {
+	tests := []struct {
+		name     string
+		clusters []string
+		wantOrg  string
+		wantOK   bool
+	}{
+		{name: "same org", clusters: []string{"root:acme:a", "root:acme", "root:acme:b:c"}, wantOrg: "root:acme", wantOK: true},
+		{name: "single cluster", clusters: []string{"root:acme:a"}, wantOrg: "root:acme", wantOK: true},
+		{name: "mixed orgs", clusters: []string{"root:acme:a", "root:other:a"}},
+		{name: "segment prefix is not the same org", clusters: []string{"root:acme:a", "root:acme2:a"}},
+		{name: "bare root", clusters: []string{"root:acme:a", "root"}},
+		{name: "invalid", clusters: []string{"root:acme:a", "abc:def"}},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clusters []logicalcluster.Name
+			for _, c := range tt.clusters {
+				clusters = append(clusters, logicalcluster.New(c))
+			}
+			org, ok := CommonOrganization(clusters)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, logicalcluster.New(tt.wantOrg), org)
+		})
+	}
+}
+
+func TestResolveWorkspacePath(t *testing.T) {
+	tests := []struct {
+		current string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{current: "root:a:b", input: "..", want: "root:a"},
+		{current: "root:a:b", input: "../x", want: "root:a:x"},
+		{current: "root:a:b", input: "../..", want: "root"},
+		{current: "root:a:b", input: "../../x:y", want: "root:x:y"},
+		{current: "root:a:b", input: "x:y", want: "root:a:b:x:y"},
+		{current: "root:a:b", input: "x/y/", want: "root:a:b:x:y"},
+		{current: "root:a:b", input: ".", want: "root:a:b"},
+		{current: "root:a:b", input: "root:abs:path", want: "root:abs:path"},
+		{current: "root:a:b", input: "system:foo", want: "system:foo"},
+		{current: "", input: "root:abs", want: "root:abs"},
+		{current: "root:a:b", input: " .. ", want: "root:a"},
+		{current: "root", input: "..", wantErr: true},
+		{current: "root:a:b", input: "../../..", wantErr: true},
+		{current: "root:a:b", input: "", wantErr: true},
+		{current: "root:a:b", input: "x::y", wantErr: true},
+		{current: "root:a:b", input: "Foo", wantErr: true},
+		{current: "root:a:b", input: "root:Abs", wantErr: true},
+		{current: "", input: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+" "+tt.input, func(t *testing.T) {
+			got, err := ResolveWorkspacePath(logicalcluster.New(tt.current), tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestClusterFromObject(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{name: "valid", annotations: map[string]string{logicalcluster.AnnotationKey: "root:foo"}, want: "root:foo"},
+		{name: "missing", annotations: map[string]string{"foo": "bar"}, wantErr: true},
+		{name: "no annotations", wantErr: true},
+		{name: "empty", annotations: map[string]string{logicalcluster.AnnotationKey: ""}, wantErr: true},
+		{name: "invalid", annotations: map[string]string{logicalcluster.AnnotationKey: "abc:def"}, wantErr: true},
+		{name: "wildcard", annotations: map[string]string{logicalcluster.AnnotationKey: "*"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Name: "cm", Annotations: tt.annotations}
+			got, err := ClusterFromObject(obj)
+			require.Equal(t, !tt.wantErr, HasValidCluster(obj))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestReservedPathSegments(t *testing.T) {
+	segments := ReservedPathSegments()
+	require.ElementsMatch(t, []string{"clusters", "services", "workspaces", "shards"}, segments)
+	for _, segment := range segments {
+		require.True(t, IsValidWorkspaceName(segment), "%q would be a valid workspace name if it was not reserved", segment)
+		require.False(t, IsRoutingSafeWorkspaceName(segment))
+	}
+
+	segments[0] = "mutated"
+	require.Contains(t, ReservedPathSegments(), ClustersPathSegment, "callers must not be able to modify the list")
+}
+
+func TestMovePreservesOrg(t *testing.T) {
+	tests := []struct {
+		source, newParent string
+		want              bool
+		wantErr           bool
+	}{
+		{source: "root:acme:a:proj", newParent: "root:acme:b", want: true},
+		{source: "root:acme:a:proj", newParent: "root:acme", want: true},
+		{source: "root:acme:a", newParent: "root", want: false},
+		{source: "root:acme", newParent: "root", want: true},
+		{source: "root:acme:a:proj", newParent: "root:other:b", want: false},
+		{source: "root:acme:a", newParent: "root:acme2", want: false},
+		{source: "root:acme:a", newParent: "system:acme", want: false},
+		{source: "root", newParent: "root:acme", wantErr: true},
+		{source: "abc:def", newParent: "root:acme", wantErr: true},
+		{source: "root:acme:a", newParent: "abc:def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source+" to "+tt.newParent, func(t *testing.T) {
+			got, err := MovePreservesOrg(logicalcluster.New(tt.source), logicalcluster.New(tt.newParent))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAggregateClusterErrors(t *testing.T) {
+	require.NoError(t, AggregateClusterErrors(nil))
+	require.NoError(t, AggregateClusterErrors(map[logicalcluster.Name]error{logicalcluster.New("root:a"): nil}))
+
+	errFoo := errors.New("foo")
+	err := AggregateClusterErrors(map[logicalcluster.Name]error{logicalcluster.New("root:a"): errFoo})
+	require.EqualError(t, err, `cluster "root:a": foo`)
+	require.ErrorIs(t, err, errFoo)
+
+	errs := map[logicalcluster.Name]error{
+		logicalcluster.New("root:c"):   errors.New("c failed"),
+		logicalcluster.New("root:a"):   errors.New("a failed"),
+		logicalcluster.New("root:b:x"): errors.New("b:x failed"),
+		logicalcluster.New("root:b"):   nil,
+	}
+	want := `[cluster "root:a": a failed, cluster "root:b:x": b:x failed, cluster "root:c": c failed]`
+	for i := 0; i < 10; i++ {
+		require.EqualError(t, AggregateClusterErrors(errs), want)
+	}
+}
+
+func TestWorkspaceLabelSelectorValidated(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "foo"},
+		{name: "foo-bar"},
+		{name: strings.Repeat("a", MaxWorkspaceSegmentLength)},
+		{name: "Foo"}, // not a workspace name, but the server accepts it in selectors
+		{name: "foo/bar", wantErr: true},
+		{name: "foo,bar", wantErr: true},
+		{name: "-foo", wantErr: true},
+		{name: strings.Repeat("a", MaxWorkspaceSegmentLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := WorkspaceLabelSelectorValidated(tt.name)
+			require.Equal(t, err, ValidateWorkspaceNameForLabel(tt.name))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, WorkspaceLabelSelector(tt.name), selector)
+			_, err = labels.Parse(selector)
+			require.NoError(t, err)
+		})
+	}
+
+	// every valid workspace name is a valid label value
+	for _, name := range []string{"a", "a1", "a-b", strings.Repeat("x", MaxWorkspaceSegmentLength)} {
+		require.True(t, IsValidWorkspaceName(name))
+		require.NoError(t, ValidateWorkspaceNameForLabel(name))
+	}
+}
+
+func TestAssignShard(t *testing.T) {
+	shards := []string{"shard-0", "shard-1", "shard-2", "shard-3"}
+
+	shard, err := AssignShard(logicalcluster.New("root:foo"), shards)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		again, err := AssignShard(logicalcluster.New("root:foo"), shards)
+		require.NoError(t, err)
+		require.Equal(t, shard, again, "assignment must be stable")
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		shard, err := AssignShard(logicalcluster.New(fmt.Sprintf("root:org-%d", i)), shards)
+		require.NoError(t, err)
+		counts[shard]++
+	}
+	require.Len(t, counts, len(shards))
+	for shard, count := range counts {
+		require.InDelta(t, 1000, count, 200, "shard %s got %d of 4000 clusters", shard, count)
+	}
+
+	_, err = AssignShard(logicalcluster.New("root:foo"), nil)
+	require.Error(t, err)
+	_, err = AssignShard(logicalcluster.New("abc:def"), shards)
+	require.Error(t, err)
+}
+
+func TestClustersFromQualifiedNames(t *testing.T) {
+	clusters, malformed := ClustersFromQualifiedNames([]string{
+		"root:b|ns/cm",
+		"root:a|crb",
+		"root:b|other",
+		"no-separator",
+		"root:a|ns/cm",
+		"abc:def|ns/cm",
+		"root:c|",
+		"root:c:d|ns/name",
+	})
+	require.Equal(t, []logicalcluster.Name{
+		logicalcluster.New("root:a"),
+		logicalcluster.New("root:b"),
+		logicalcluster.New("root:c:d"),
+	}, clusters)
+	require.Equal(t, []string{"no-separator", "abc:def|ns/cm", "root:c|"}, malformed)
+
+	clusters, malformed = ClustersFromQualifiedNames(nil)
+	require.Empty(t, clusters)
+	require.Empty(t, malformed)
+}
+
+func TestParentCluster(t *testing.T) {
+	parent, ok := ParentCluster(logicalcluster.New("root:a:b"))
+	require.True(t, ok)
+	require.Equal(t, logicalcluster.New("root:a"), parent)
+
+	parent, ok = ParentCluster(logicalcluster.New("root:a"))
+	require.True(t, ok)
+	require.Equal(t, logicalcluster.New("root"), parent)
+
+	_, ok = ParentCluster(logicalcluster.New("root"))
+	require.False(t, ok)
+	_, ok = ParentCluster(logicalcluster.New("abc:def"))
+	require.False(t, ok)
+}
+
+func TestIsDirectChild(t *testing.T) {
+	tests := []struct {
+		parent, child string
+		want          bool
+	}{
+		{parent: "root", child: "root:a", want: true},
+		{parent: "root:a", child: "root:a:b", want: true},
+		{parent: "root", child: "root:a:b"},
+		{parent: "root:a", child: "root:b"},
+		{parent: "root:a", child: "root:a"},
+		{parent: "root:a", child: "root:ab"},
+		{parent: "root:a", child: "root:ab:c"},
+		{parent: "root:a:b", child: "root:a"},
+		{parent: "", child: "root"},
+		{parent: "abc", child: "abc:def"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.parent+" "+tt.child, func(t *testing.T) {
+			require.Equal(t, tt.want, IsDirectChild(logicalcluster.New(tt.parent), logicalcluster.New(tt.child)))
+		})
+	}
+}
+
+func TestClusterMetricLabel(t *testing.T) {
+	for _, c := range []string{"root", "root:acme:prod", "system:foo-bar"} {
+		require.Equal(t, c, ClusterMetricLabel(logicalcluster.New(c)))
+	}
+
+	require.Equal(t, "root:a_b_c_", ClusterMetricLabel(logicalcluster.New("root:a\nb\x00c\u00e9")))
+
+	long := logicalcluster.New("root:" + strings.Repeat("a", 60) + ":" + strings.Repeat("b", 60) + ":" + strings.Repeat("c", 60))
+	label := ClusterMetricLabel(long)
+	require.Len(t, label, MaxClusterMetricLabelLength)
+	require.Equal(t, label, ClusterMetricLabel(long), "labels must be deterministic")
+
+	other := logicalcluster.New(long.String() + "x")
+	require.NotEqual(t, label, ClusterMetricLabel(other), "names sharing a long prefix must not collide")
+	require.Len(t, ClusterMetricLabel(other), MaxClusterMetricLabelLength)
+}
+
+func TestClusterFromHeader(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "root:foo", want: "root:foo"},
+		{value: " root:foo:bar\t", want: "root:foo:bar"},
+		{value: "system:admin", want: "system:admin"},
+		{value: "", wantErr: true},
+		{value: "   ", wantErr: true},
+		{value: "abc:def", wantErr: true},
+		{value: "root:Foo", wantErr: true},
+		{value: "*", wantErr: true},
+		{value: "root:foo, root:bar", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ClusterFromHeader(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.want), got)
+		})
+	}
+}
+
+func TestSegments(t *testing.T) {
+	tests := []struct {
+		cluster string
+		want    []string
+		wantErr bool
+	}{
+		{cluster: "root", want: []string{"root"}},
+		{cluster: "system", want: []string{"system"}},
+		{cluster: "system:admin", want: []string{"system", "admin"}},
+		{cluster: "root:acme:web:prod", want: []string{"root", "acme", "web", "prod"}},
+		{cluster: "", wantErr: true},
+		{cluster: "abc:def", wantErr: true},
+		{cluster: "root::foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			got, err := Segments(logicalcluster.New(tt.cluster))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAllSameCluster(t *testing.T) {
+	obj := func(name, cluster string) metav1.Object {
+		o := &metav1.ObjectMeta{Name: name, Namespace: "ns"}
+		if cluster != "" {
+			o.Annotations = map[string]string{logicalcluster.AnnotationKey: cluster}
+		}
+		return o
+	}
+
+	cluster, err := AllSameCluster([]metav1.Object{obj("a", "root:foo"), obj("b", "root:foo"), obj("c", "root:foo")})
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.New("root:foo"), cluster)
+
+	cluster, err = AllSameCluster([]metav1.Object{obj("a", "root:foo")})
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.New("root:foo"), cluster)
+
+	_, err = AllSameCluster([]metav1.Object{obj("a", "root:foo"), obj("b", "root:foo"), obj("c", "root:bar")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"ns/c"`)
+
+	_, err = AllSameCluster([]metav1.Object{obj("a", "root:foo"), obj("b", "")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"ns/b"`)
+
+	_, err = AllSameCluster([]metav1.Object{obj("a", "abc:def"), obj("b", "abc:def")})
+	require.Error(t, err)
+
+	_, err = AllSameCluster(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+)
+
+var patternSegmentRegExp = regexp.MustCompile(`^[a-z0-9*-]+$`)
+
+// MatchesAnyPattern returns true if cluster matches at least one of the glob
+// patterns. Patterns are colon separated lists of segments, like cluster
+// names, and are matched segment by segment:
+//
+//   - a "*" inside a segment matches any, possibly empty, sequence of
+//     characters within that segment, e.g. root:team-* matches root:team-a
+//     but neither root:team-a:b nor root:other;
+//   - a segment consisting of exactly "**" matches zero or more whole
+//     segments, e.g. root:** matches root, root:a and root:a:b;
+//   - any other character matches itself.
+//
+// A pattern is malformed if it has empty segments, characters other than
+// lower-case alphanumerics, "-" and "*", or "**" mixed with other characters
+// in a segment. Any malformed pattern yields an error.
+func MatchesAnyPattern(cluster logicalcluster.Name, patterns []string) (bool, error) {
+	segments := splitCluster(cluster)
+	matched := false
+	for _, pattern := range patterns {
+		compiled, err := compileClusterPattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		if !matched && matchPatternSegments(compiled, segments) {
+			matched = true
+		}
+	}
+	return matched, nil
+}
+
+// compileClusterPattern compiles each segment of pattern into a regular
+// expression. "**" segments are represented by nil.
+func compileClusterPattern(pattern string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, segment := range strings.Split(pattern, ":") {
+		if !patternSegmentRegExp.MatchString(segment) {
+			return nil, fmt.Errorf("invalid cluster pattern %q: segment %q must be non-empty and consist of lower-case alphanumerics, '-' and '*'", pattern, segment)
+		}
+		if segment == "**" {
+			compiled = append(compiled, nil)
+			continue
+		}
+		if strings.Contains(segment, "**") {
+			return nil, fmt.Errorf("invalid cluster pattern %q: '**' must be a segment of its own", pattern)
+		}
+		parts := strings.Split(segment, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		compiled = append(compiled, regexp.MustCompile("^"+strings.Join(parts, ".*")+"$"))
+	}
+	return compiled, nil
+}
+
+func matchPatternSegments(pattern []*regexp.Regexp, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == nil {
+		for i := 0; i <= len(segments); i++ {
+			if matchPatternSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	return pattern[0].MatchString(segments[0]) && matchPatternSegments(pattern[1:], segments[1:])
+}
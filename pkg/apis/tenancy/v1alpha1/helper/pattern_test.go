@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesAnyPattern(t *testing.T) {
+	tests := []struct {
+		cluster  string
+		patterns []string
+		want     bool
+		wantErr  bool
+	}{
+		// single segment wildcards
+		{cluster: "root:team-a", patterns: []string{"root:team-*"}, want: true},
+		{cluster: "root:team-", patterns: []string{"root:team-*"}, want: true},
+		{cluster: "root:team-a:proj", patterns: []string{"root:team-*"}, want: false},
+		{cluster: "root:other", patterns: []string{"root:team-*"}, want: false},
+		{cluster: "root:a:b", patterns: []string{"root:*:b"}, want: true},
+		{cluster: "root:a:x:b", patterns: []string{"root:*:b"}, want: false},
+		{cluster: "root:prod-eu-1", patterns: []string{"root:*-eu-*"}, want: true},
+
+		// multi segment wildcards
+		{cluster: "root", patterns: []string{"root:**"}, want: true},
+		{cluster: "root:a", patterns: []string{"root:**"}, want: true},
+		{cluster: "root:a:b:c", patterns: []string{"root:**"}, want: true},
+		{cluster: "system:a", patterns: []string{"root:**"}, want: false},
+		{cluster: "root:a:b:proj", patterns: []string{"root:**:proj"}, want: true},
+		{cluster: "root:proj", patterns: []string{"root:**:proj"}, want: true},
+		{cluster: "root:a:proj:b", patterns: []string{"root:**:proj"}, want: false},
+		{cluster: "system:a", patterns: []string{"**"}, want: true},
+
+		// exact and multiple patterns
+		{cluster: "root:a", patterns: []string{"root:a"}, want: true},
+		{cluster: "root:ab", patterns: []string{"root:a"}, want: false},
+		{cluster: "root:b", patterns: []string{"root:a", "root:b"}, want: true},
+		{cluster: "root:c", patterns: []string{"root:a", "root:b"}, want: false},
+		{cluster: "root:a", patterns: nil, want: false},
+
+		// malformed patterns
+		{cluster: "root:a", patterns: []string{"root::a"}, wantErr: true},
+		{cluster: "root:a", patterns: []string{"root:a**"}, wantErr: true},
+		{cluster: "root:a", patterns: []string{"root:A"}, wantErr: true},
+		{cluster: "root:a", patterns: []string{"root:a.b"}, wantErr: true},
+		{cluster: "root:a", patterns: []string{""}, wantErr: true},
+		{cluster: "root:a", patterns: []string{"root:a", "root:[a]"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster+" "+strings.Join(tt.patterns, ","), func(t *testing.T) {
+			got, err := MatchesAnyPattern(logicalcluster.New(tt.cluster), tt.patterns)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
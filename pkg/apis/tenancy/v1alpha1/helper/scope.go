@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+)
+
+// ClusterScope restricts a component to the subtree of a logical cluster, e.g.
+// a regional controller to root:region-eu and its descendants.
+type ClusterScope struct {
+	root logicalcluster.Name
+}
+
+// NewClusterScope creates a ClusterScope for the subtree rooted at root.
+func NewClusterScope(root logicalcluster.Name) (ClusterScope, error) {
+	if !IsValidCluster(root) {
+		return ClusterScope{}, fmt.Errorf("invalid scope root %q", root)
+	}
+	return ClusterScope{root: root}, nil
+}
+
+// Root returns the root of the scope.
+func (s ClusterScope) Root() logicalcluster.Name {
+	return s.root
+}
+
+// InScope indicates whether cluster is the root of the scope or one of its
+// descendants.
+func (s ClusterScope) InScope(cluster logicalcluster.Name) bool {
+	return !s.root.Empty() && IsValidCluster(cluster) && hasSegmentPrefix(cluster, s.root)
+}
+
+// Validate returns an error if cluster is not in scope.
+func (s ClusterScope) Validate(cluster logicalcluster.Name) error {
+	if !IsValidCluster(cluster) {
+		return fmt.Errorf("invalid cluster %q", cluster)
+	}
+	if !s.InScope(cluster) {
+		return fmt.Errorf("cluster %q is out of scope, only %s and its descendants are handled", cluster, s.root)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterScope(t *testing.T) {
+	scope, err := NewClusterScope(logicalcluster.New("root:region-eu"))
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.New("root:region-eu"), scope.Root())
+
+	tests := []struct {
+		cluster string
+		inScope bool
+	}{
+		{cluster: "root:region-eu", inScope: true},
+		{cluster: "root:region-eu:acme", inScope: true},
+		{cluster: "root:region-eu:acme:prod", inScope: true},
+		{cluster: "root:region-eu-west", inScope: false},
+		{cluster: "root:region-us", inScope: false},
+		{cluster: "root", inScope: false},
+		{cluster: "system:region-eu", inScope: false},
+		{cluster: "", inScope: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			require.Equal(t, tt.inScope, scope.InScope(logicalcluster.New(tt.cluster)))
+			err := scope.Validate(logicalcluster.New(tt.cluster))
+			if tt.inScope {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	_, err = NewClusterScope(logicalcluster.New("abc:def"))
+	require.Error(t, err)
+
+	require.False(t, ClusterScope{}.InScope(logicalcluster.New("root")), "the zero scope must not contain anything")
+}
@@ -31,6 +31,9 @@ import (
 // RootCluster is the root of ClusterWorkspace based logical clusters.
 var RootCluster = logicalcluster.New("root")
 
+// SystemCluster is the root of logical clusters used internally by kcp.
+var SystemCluster = logicalcluster.New("system")
+
 // RootShard holds a name of the root shard.
 var RootShard = "root"
 
@@ -252,6 +255,92 @@ type ClusterWorkspaceTypeSpec struct {
 	// +listMapKey=path
 	// +listMapKey=exportName
 	DefaultAPIBindings []APIExportReference `json:"defaultAPIBindings,omitempty"`
+
+	// quota restricts the resources that workspaces of this type can consume. It is
+	// enforced by admission when objects are created in a workspace of this type.
+	// Extending another ClusterWorkspaceType does not inherit its quota.
+	//
+	// +optional
+	Quota *ClusterWorkspaceTypeQuota `json:"quota,omitempty"`
+
+	// bootstrapResources references manifests of objects that are created in every
+	// workspace of this type during initialization, e.g. RoleBindings, APIBindings
+	// or default objects. The objects are created as the user creating the workspace.
+	// Extending another ClusterWorkspaceType inherits its bootstrapResources.
+	//
+	// +optional
+	BootstrapResources *BootstrapResources `json:"bootstrapResources,omitempty"`
+}
+
+// BootstrapResources references manifests of objects to create in a new workspace.
+type BootstrapResources struct {
+	// configMap references a ConfigMap in the workspace of the ClusterWorkspaceType.
+	// Every data key of the ConfigMap holds one or more YAML documents.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	ConfigMap ConfigMapReference `json:"configMap"`
+}
+
+// ConfigMapReference references a ConfigMap in the same workspace.
+type ConfigMapReference struct {
+	// namespace is the namespace of the ConfigMap.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// name is the name of the ConfigMap.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ClusterWorkspaceTypeQuota restricts the resources a workspace can consume.
+type ClusterWorkspaceTypeQuota struct {
+	// maxNamespaces is the maximal number of namespaces in a workspace.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxNamespaces *int64 `json:"maxNamespaces,omitempty"`
+
+	// maxChildWorkspaces is the maximal number of ClusterWorkspaces created directly
+	// in a workspace.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxChildWorkspaces *int64 `json:"maxChildWorkspaces,omitempty"`
+
+	// maxObjects limits the number of objects of individual resources in a workspace,
+	// e.g. of the resources of APIs bound in the workspace.
+	//
+	// +optional
+	MaxObjects []ResourceObjectQuota `json:"maxObjects,omitempty"`
+}
+
+// ResourceObjectQuota limits the number of objects of a resource.
+type ResourceObjectQuota struct {
+	// group is the API group of the resource. It is empty for the core group.
+	//
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// resource is the lower-case plural name of the resource, e.g. configmaps.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Resource string `json:"resource"`
+
+	// max is the maximal number of objects of the resource.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Max int64 `json:"max"`
 }
 
 // APIExportReference provides the fields necessary to resolve an APIExport.
@@ -375,6 +464,10 @@ type ClusterWorkspaceInitializer string
 // on a ClusterWorkspaceType to be created.
 const ClusterWorkspaceAPIBindingsInitializer ClusterWorkspaceInitializer = "system:apibindings"
 
+// ClusterWorkspaceBootstrapResourcesInitializer is a special-case initializer that creates the objects
+// referenced by the bootstrapResources of a ClusterWorkspaceType.
+const ClusterWorkspaceBootstrapResourcesInitializer ClusterWorkspaceInitializer = "system:bootstrapresources"
+
 // ClusterWorkspacePhaseType is the type of the current phase of the workspace
 type ClusterWorkspacePhaseType string
 
@@ -382,13 +475,20 @@ const (
 	ClusterWorkspacePhaseScheduling   ClusterWorkspacePhaseType = "Scheduling"
 	ClusterWorkspacePhaseInitializing ClusterWorkspacePhaseType = "Initializing"
 	ClusterWorkspacePhaseReady        ClusterWorkspacePhaseType = "Ready"
+	// ClusterWorkspacePhaseTrashed is the phase of a deleted workspace whose content is retained
+	// until the retention period is over. Until then, it can be restored.
+	ClusterWorkspacePhaseTrashed ClusterWorkspacePhaseType = "Trashed"
 )
 
 const ExperimentalClusterWorkspaceOwnerAnnotationKey string = "experimental.tenancy.kcp.dev/owner"
 
+// ExperimentalClusterWorkspaceRestoreAnnotationKey is set to "true" on a trashed ClusterWorkspace to
+// recreate it with its retained content.
+const ExperimentalClusterWorkspaceRestoreAnnotationKey string = "experimental.tenancy.kcp.dev/restore"
+
 // ClusterWorkspaceStatus communicates the observed state of the ClusterWorkspace.
 type ClusterWorkspaceStatus struct {
-	// Phase of the workspace  (Scheduling / Initializing / Ready)
+	// Phase of the workspace  (Scheduling / Initializing / Ready / Trashed)
 	Phase ClusterWorkspacePhaseType `json:"phase,omitempty"`
 
 	// Current processing state of the ClusterWorkspace.
@@ -464,6 +564,12 @@ const (
 	// WorkspaceInitializedAPIBindingErrors is a reason for the APIBindingsInitialized condition that indicates there
 	// were errors trying to initialize APIBindings for the workspace.
 	WorkspaceInitializedAPIBindingErrors = "APIBindingErrors"
+
+	// WorkspaceBootstrapResourcesInitialized represents the status of the bootstrap resources of the workspace.
+	WorkspaceBootstrapResourcesInitialized conditionsv1alpha1.ConditionType = "BootstrapResourcesInitialized"
+	// WorkspaceInitializedBootstrapResourcesErrors is a reason for the BootstrapResourcesInitialized condition
+	// that indicates there were errors trying to create the bootstrap resources in the workspace.
+	WorkspaceInitializedBootstrapResourcesErrors = "BootstrapResourcesErrors"
 )
 
 // ClusterWorkspaceLocation specifies workspace placement information, including current, desired (target), and
@@ -45,6 +45,23 @@ func (in *APIExportReference) DeepCopy() *APIExportReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapResources) DeepCopyInto(out *BootstrapResources) {
+	*out = *in
+	out.ConfigMap = in.ConfigMap
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapResources.
+func (in *BootstrapResources) DeepCopy() *BootstrapResources {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspace) DeepCopyInto(out *ClusterWorkspace) {
 	*out = *in
@@ -362,6 +379,37 @@ func (in *ClusterWorkspaceTypeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceTypeQuota) DeepCopyInto(out *ClusterWorkspaceTypeQuota) {
+	*out = *in
+	if in.MaxNamespaces != nil {
+		in, out := &in.MaxNamespaces, &out.MaxNamespaces
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxChildWorkspaces != nil {
+		in, out := &in.MaxChildWorkspaces, &out.MaxChildWorkspaces
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxObjects != nil {
+		in, out := &in.MaxObjects, &out.MaxObjects
+		*out = make([]ResourceObjectQuota, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceTypeQuota.
+func (in *ClusterWorkspaceTypeQuota) DeepCopy() *ClusterWorkspaceTypeQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceTypeQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceTypeReference) DeepCopyInto(out *ClusterWorkspaceTypeReference) {
 	*out = *in
@@ -430,6 +478,16 @@ func (in *ClusterWorkspaceTypeSpec) DeepCopyInto(out *ClusterWorkspaceTypeSpec)
 		*out = make([]APIExportReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ClusterWorkspaceTypeQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootstrapResources != nil {
+		in, out := &in.BootstrapResources, &out.BootstrapResources
+		*out = new(BootstrapResources)
+		**out = **in
+	}
 	return
 }
 
@@ -471,6 +529,38 @@ func (in *ClusterWorkspaceTypeStatus) DeepCopy() *ClusterWorkspaceTypeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceObjectQuota) DeepCopyInto(out *ResourceObjectQuota) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceObjectQuota.
+func (in *ResourceObjectQuota) DeepCopy() *ResourceObjectQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceObjectQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShardConstraints) DeepCopyInto(out *ShardConstraints) {
 	*out = *in
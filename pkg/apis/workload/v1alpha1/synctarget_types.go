@@ -80,6 +80,35 @@ type SyncTargetSpec struct {
 	// they are in the same physical cluster. Each key/value pair in the cells should be added and updated by service providers
 	// (i.e. a network provider updates one key/value, while the storage provider updates another.)
 	Cells map[string]string `json:"cells,omitempty"`
+
+	// StorageClasses maps the storage classes requested by persistent volume claims in kcp, e.g. in the
+	// volumeClaimTemplates of StatefulSets, to the storage classes of the SyncTarget. Claims requesting
+	// a storage class that is not mapped are synced unchanged.
+	//
+	// Claim templates of StatefulSets are immutable. Changing the mapping of a storage class that
+	// synced StatefulSets already use makes their updates fail downstream.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=upstream
+	StorageClasses []StorageClassMapping `json:"storageClasses,omitempty"`
+}
+
+// StorageClassMapping maps a storage class name used in kcp to a storage class name of the SyncTarget.
+type StorageClassMapping struct {
+	// upstream is the name of the storage class requested in kcp.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Upstream string `json:"upstream"`
+
+	// downstream is the name of the storage class used on the SyncTarget instead.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Downstream string `json:"downstream"`
 }
 
 // SyncTargetStatus communicates the observed state of the SyncTarget (from the controller).
@@ -114,6 +114,11 @@ const (
 	// instead of state.workload.kcp.dev/<sync-target-name> which is used upstream.
 	InternalDownstreamClusterLabel = "internal.workload.kcp.dev/cluster"
 
+	// InternalUpstreamGenerationAnnotation is an annotation the syncer sets on downstream resources, holding the
+	// generation of the upstream resource whose spec was last synced downstream. It is used to translate the
+	// observed generation of the downstream status back into an upstream generation.
+	InternalUpstreamGenerationAnnotation = "internal.workload.kcp.dev/upstream-generation"
+
 	// AnnotationSkipDefaultObjectCreation is the annotation key for an apiexport or apibinding indicating the other default resources
 	// has been created already. If the created default resource is deleted, it will not be recreated.
 	AnnotationSkipDefaultObjectCreation = "workload.kcp.dev/skip-default-object-creation"
@@ -52,6 +52,22 @@ func (in *ResourceToSync) DeepCopy() *ResourceToSync {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassMapping) DeepCopyInto(out *StorageClassMapping) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMapping.
+func (in *StorageClassMapping) DeepCopy() *StorageClassMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncTarget) DeepCopyInto(out *SyncTarget) {
 	*out = *in
@@ -134,6 +150,11 @@ func (in *SyncTargetSpec) DeepCopyInto(out *SyncTargetSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]StorageClassMapping, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
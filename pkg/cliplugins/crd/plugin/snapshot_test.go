@@ -49,6 +49,52 @@ func TestSnapshot(t *testing.T) {
 	require.Empty(t, cmp.Diff(expectedYAML, strings.Trim(stdout.String(), "\n")))
 }
 
+func TestSnapshotConversionWebhook(t *testing.T) {
+	streams, stdin, _, _ := genericclioptions.NewTestIOStreams()
+
+	opts := NewSnapshotOptions(streams)
+	opts.Prefix = "testing"
+	opts.Filename = "-"
+
+	_, err := stdin.WriteString(conversionWebhookCRDYaml)
+	require.NoError(t, err)
+
+	require.NoError(t, opts.Validate())
+	require.NoError(t, opts.Complete())
+
+	err = opts.Run()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `spec.conversion.strategy: Unsupported value: "Webhook"`)
+}
+
+var conversionWebhookCRDYaml = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.dev
+spec:
+  group: example.dev
+  names:
+    kind: Widget
+    listKind: WidgetList
+    plural: widgets
+    singular: widget
+  scope: Namespaced
+  conversion:
+    strategy: Webhook
+    webhook:
+      conversionReviewVersions: ["v1"]
+      clientConfig:
+        url: https://widgets.example.dev/convert
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+    served: true
+    storage: true
+`
+
 var multiCRDYaml = `
 apiVersion: apiextensions.k8s.io/v1
 kind: CustomResourceDefinition
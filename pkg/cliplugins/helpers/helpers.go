@@ -17,37 +17,678 @@ limitations under the License.
 package helpers
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"path"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
 	virtualcommandoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
 	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 )
 
+// ErrInvalidClusterName is returned when parsing a URL which has a cluster
+// segment, but the cluster in it is invalid. URLs without a cluster segment
+// fail with a different error.
+var ErrInvalidClusterName = errors.New("invalid cluster name")
+
+// ErrBaseURLMismatch is returned when a cluster URL does not belong to the
+// expected base URL, e.g. of a shard.
+var ErrBaseURLMismatch = errors.New("base URL mismatch")
+
+// ErrHostNotAllowed is returned by ParseClusterURLWithAllowedHosts for cluster
+// URLs whose host is not in the allow-list.
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// ErrMissingClusterPrefix is returned by ValidateClusterRequestPath for paths
+// without a /clusters/<cluster> prefix.
+var ErrMissingClusterPrefix = errors.New("missing cluster prefix")
+
+// ErrInvalidAPIPath is returned by ValidateClusterRequestPath when the path
+// following the cluster is not a Kubernetes API path.
+var ErrInvalidAPIPath = errors.New("invalid API path")
+
+// ClusterURLKind identifies which of the path forms understood by
+// ParseClusterURL addresses the cluster in a URL.
+type ClusterURLKind int
+
+const (
+	// ClustersPath is the plain /clusters/<cluster> form served by kcp.
+	ClustersPath ClusterURLKind = iota + 1
+	// WorkspacesVirtualPath is the /services/workspaces/<cluster> form served
+	// by the workspaces virtual workspace.
+	WorkspacesVirtualPath
+)
+
+func (k ClusterURLKind) String() string {
+	switch k {
+	case ClustersPath:
+		return "ClustersPath"
+	case WorkspacesVirtualPath:
+		return "WorkspacesVirtualPath"
+	default:
+		return fmt.Sprintf("ClusterURLKind(%d)", int(k))
+	}
+}
+
+func (k ClusterURLKind) prefix() string {
+	for _, p := range clusterURLPrefixes {
+		if p.kind == k {
+			return p.prefix
+		}
+	}
+	return ""
+}
+
+type clusterURLPrefix struct {
+	prefix string
+	kind   ClusterURLKind
+}
+
+// clusterURLPrefixes are the path prefixes that precede the cluster segment,
+// in the order they are matched.
+var clusterURLPrefixes = newClusterURLPrefixes(tenancyhelper.ReservedPathSegments())
+
+// newClusterURLPrefixes derives the cluster URL prefixes from the reserved
+// path segments, such that the parser only matches on reserved segments.
+func newClusterURLPrefixes(reserved []string) []clusterURLPrefix {
+	var prefixes []clusterURLPrefix
+	for _, segment := range reserved {
+		switch segment {
+		case tenancyhelper.ClustersPathSegment:
+			prefixes = append(prefixes, clusterURLPrefix{prefix: "/" + segment + "/", kind: ClustersPath})
+		case tenancyhelper.WorkspacesPathSegment:
+			prefixes = append(prefixes, clusterURLPrefix{prefix: path.Join(virtualcommandoptions.DefaultRootPathPrefix, segment) + "/", kind: WorkspacesVirtualPath})
+		}
+	}
+	return prefixes
+}
+
+// ClusterURL is a cluster URL split into its components.
+type ClusterURL struct {
+	// Base is the URL up to the cluster path prefix, e.g. https://host for
+	// https://host/clusters/root:foo.
+	Base *url.URL
+	// Kind is the path form used to address the cluster.
+	Kind ClusterURLKind
+	// Cluster is the logical cluster addressed by the URL.
+	Cluster logicalcluster.Name
+	// Remainder is the path following the cluster segment, e.g. /api/v1 for
+	// https://host/clusters/root:foo/api/v1 or /healthz for a health probe of
+	// the cluster. It is empty if there is none.
+	Remainder string
+}
+
+// IsRoot returns true if the URL addresses the top of a cluster hierarchy,
+// i.e. exactly root or system, which has no parent to navigate to.
+func (u *ClusterURL) IsRoot() bool {
+	_, hasParent := u.Cluster.Parent()
+	return !hasParent
+}
+
+// ParseClusterURL splits a cluster URL like https://host/clusters/root:foo into
+// the base URL https://host and the logical cluster root:foo. Only http and
+// https URLs with a non-empty host are accepted. The query string of host is
+// kept on the returned base URL so that callers rebuilding a request from the
+// base do not lose parameters like watch or resourceVersion.
 func ParseClusterURL(host string) (*url.URL, logicalcluster.Name, error) {
+	u, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return nil, logicalcluster.Name{}, err
+	}
+	return u.Base, u.Cluster, nil
+}
+
+// ParseClusterURLKind is like ParseClusterURL, but reports which path form
+// the cluster was addressed with instead of the base URL.
+func ParseClusterURLKind(host string) (ClusterURLKind, logicalcluster.Name, error) {
+	u, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return 0, logicalcluster.Name{}, err
+	}
+	return u.Kind, u.Cluster, nil
+}
+
+// ParseClusterURLDetails is like ParseClusterURL, but returns all components
+// of the URL at once.
+func ParseClusterURLDetails(host string) (*ClusterURL, error) {
+	u, err := parseHTTPURL(host)
+	if err != nil {
+		return nil, err
+	}
+	basePath, kind, cluster, remainder, found := splitClusterPath(u.Path)
+	if !found || cluster == "" {
+		return nil, fmt.Errorf("current cluster URL %s is not pointing to a cluster workspace", u)
+	}
+	clusterName := logicalcluster.New(cluster)
+	if !tenancyhelper.IsValidCluster(clusterName) {
+		reason := "is not rooted at root or system"
+		if !clusterName.IsValid() {
+			reason = "does not adhere to logical cluster naming requirements"
+		}
+		return nil, fmt.Errorf("%w: cluster %q of URL %s %s", ErrInvalidClusterName, clusterName, u, reason)
+	}
+
+	ret := *u
+	ret.Path = basePath
+	ret.RawPath = ""
+	return &ClusterURL{Base: &ret, Kind: kind, Cluster: clusterName, Remainder: remainder}, nil
+}
+
+// parseHTTPURL parses host as an http or https URL with a non-empty host.
+func parseHTTPURL(host string) (*url.URL, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("cluster URL %q must use the http or https scheme", host)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("cluster URL %q is missing a host", host)
+	}
+	return u, nil
+}
+
+// splitClusterPath splits a URL path at the first of the cluster path
+// prefixes into the path before the prefix, the unvalidated cluster segment
+// and the path following the cluster segment. Prefixes only match at segment
+// boundaries, and the earliest match wins, such that a prefix occurring in the
+// remainder does not shadow the actual one.
+func splitClusterPath(urlPath string) (basePath string, kind ClusterURLKind, cluster, remainder string, found bool) {
+	clusterIndex := -1
+	var prefix string
+	for _, p := range clusterURLPrefixes {
+		if i := strings.Index(urlPath, p.prefix); i >= 0 && (clusterIndex < 0 || i < clusterIndex) {
+			clusterIndex, prefix, kind = i, p.prefix, p.kind
+		}
+	}
+	if clusterIndex < 0 {
+		return "", 0, "", "", false
+	}
+	parts := strings.SplitN(urlPath[clusterIndex+len(prefix):], "/", 2)
+	if len(parts) > 1 {
+		remainder = "/" + parts[1]
+	}
+	return urlPath[:clusterIndex], kind, parts[0], remainder, true
+}
+
+// ClusterFromConfigHost returns the logical cluster of a rest.Config host
+// like https://host/clusters/root:foo, discarding the base URL. It fails
+// exactly when ParseClusterURL does.
+func ClusterFromConfigHost(host string) (logicalcluster.Name, error) {
+	_, cluster, err := ParseClusterURL(host)
+	if err != nil {
+		return logicalcluster.Name{}, err
+	}
+	return cluster, nil
+}
+
+// CanonicalClusterURL returns a stable spelling of a cluster URL so that URLs
+// addressing the same cluster compare equal as strings. The scheme and host are
+// lowercased, a single trailing dot of a fully qualified host name is removed,
+// the default port of the scheme is dropped, the base path is cleaned, and
+// anything after the cluster segment, including the query, is removed. The
+// path form, /clusters/ or the workspaces virtual workspace, is preserved.
+func CanonicalClusterURL(host string) (string, error) {
+	parsed, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return "", err
+	}
+
+	u := parsed.Base
+	canonical := url.URL{
+		Scheme: strings.ToLower(u.Scheme),
+		Host:   canonicalHost(u.Scheme, u.Host),
+	}
+	basePath := path.Clean("/" + u.Path)
+	if basePath == "/" {
+		basePath = ""
+	}
+	canonical.Path = basePath + parsed.Kind.prefix() + parsed.Cluster.String()
+
+	return canonical.String(), nil
+}
+
+// canonicalHost lowercases hostport, removes a single trailing dot of a fully
+// qualified host name and drops the default port of scheme.
+func canonicalHost(scheme, hostport string) string {
+	hostname, port := hostport, ""
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		hostname, port = h, p
+	}
+	// A trailing dot marks a fully qualified host name, as appended by some
+	// service meshes. It addresses the same host, so it is dropped on purpose.
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(hostname, "["), "]")), ".")
+	scheme = strings.ToLower(scheme)
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		port = ""
+	}
+	if port != "" {
+		return net.JoinHostPort(hostname, port)
+	}
+	if strings.Contains(hostname, ":") {
+		// IPv6 literals have to stay bracketed without a port, too.
+		return "[" + hostname + "]"
+	}
+	return hostname
+}
+
+// ParseClusterSubdomainURL splits a URL addressing the cluster by subdomain,
+// like https://root-foo.kcp.example.com/api, into the base URL
+// https://kcp.example.com and the logical cluster root:foo. The leftmost host
+// label must be directly followed by domainSuffix, and it encodes the cluster
+// with each ":" replaced by "-". As that encoding cannot tell a separator from a
+// hyphen inside a segment, every "-" is decoded as ":", i.e. only clusters
+// without hyphens in their segments can be addressed this way. The path of the
+// URL is dropped from the base, like everything after the cluster segment is
+// by ParseClusterURL.
+func ParseClusterSubdomainURL(host, domainSuffix string) (*url.URL, logicalcluster.Name, error) {
 	u, err := url.Parse(host)
 	if err != nil {
 		return nil, logicalcluster.Name{}, err
 	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, logicalcluster.Name{}, fmt.Errorf("cluster URL %q must use the http or https scheme", host)
+	}
+	domainSuffix = strings.ToLower(strings.Trim(domainSuffix, "."))
+	if domainSuffix == "" {
+		return nil, logicalcluster.Name{}, fmt.Errorf("domain suffix must not be empty")
+	}
+
+	hostname := strings.ToLower(u.Hostname())
+	label := strings.TrimSuffix(hostname, "."+domainSuffix)
+	if label == hostname || label == "" || strings.Contains(label, ".") {
+		return nil, logicalcluster.Name{}, fmt.Errorf("cluster URL %q does not address a cluster as a subdomain of %q", host, domainSuffix)
+	}
+	clusterName := logicalcluster.New(strings.ReplaceAll(label, "-", ":"))
+	if !tenancyhelper.IsValidCluster(clusterName) {
+		return nil, logicalcluster.Name{}, fmt.Errorf("subdomain %q of cluster URL %q is not a valid cluster", label, host)
+	}
+
 	ret := *u
-	var clusterName logicalcluster.Name
-	for _, prefix := range []string{
-		"/clusters/",
-		path.Join(virtualcommandoptions.DefaultRootPathPrefix, "workspaces") + "/",
-	} {
-		if clusterIndex := strings.Index(u.Path, prefix); clusterIndex >= 0 {
-			clusterName = logicalcluster.New(strings.SplitN(ret.Path[clusterIndex+len(prefix):], "/", 2)[0])
-			ret.Path = ret.Path[:clusterIndex]
+	ret.Host = domainSuffix
+	if port := u.Port(); port != "" {
+		ret.Host = net.JoinHostPort(domainSuffix, port)
+	}
+	ret.Path = ""
+	ret.RawPath = ""
+
+	return &ret, clusterName, nil
+}
+
+// ReplaceClusterInURL returns host with its cluster segment replaced by
+// newCluster. The path form, the path following the cluster and the query are
+// preserved.
+func ReplaceClusterInURL(host string, newCluster logicalcluster.Name) (string, error) {
+	if !tenancyhelper.IsValidCluster(newCluster) {
+		return "", fmt.Errorf("invalid cluster %q", newCluster)
+	}
+	parsed, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return "", err
+	}
+	ret := *parsed.Base
+	ret.Path = parsed.Base.Path + parsed.Kind.prefix() + newCluster.String() + parsed.Remainder
+	return ret.String(), nil
+}
+
+// SameBaseURL returns true if the cluster URLs a and b share the same scheme,
+// host and path prefix, regardless of the cluster they address and of what
+// follows the cluster segment.
+func SameBaseURL(a, b string) (bool, error) {
+	aURL, _, err := ParseClusterURL(a)
+	if err != nil {
+		return false, err
+	}
+	bURL, _, err := ParseClusterURL(b)
+	if err != nil {
+		return false, err
+	}
+	return sameBase(aURL, bURL), nil
+}
+
+func sameBase(a, b *url.URL) bool {
+	return a.Scheme == b.Scheme && a.Host == b.Host && a.Path == b.Path
+}
+
+// ValidateClusterForBase checks that host is a URL of a valid cluster that is
+// served under expectedBase, e.g. the base URL of the shard the cluster is
+// paired with in a kubeconfig. A cluster that is not valid fails with
+// ErrInvalidClusterName, one under another base with ErrBaseURLMismatch.
+func ValidateClusterForBase(host string, expectedBase *url.URL) error {
+	base, _, err := ParseClusterURL(host)
+	if err != nil {
+		return err
+	}
+	if base.Scheme != expectedBase.Scheme || base.Host != expectedBase.Host || strings.TrimSuffix(base.Path, "/") != strings.TrimSuffix(expectedBase.Path, "/") {
+		return fmt.Errorf("%w: cluster URL %q is not served by %s", ErrBaseURLMismatch, host, expectedBase)
+	}
+	return nil
+}
+
+// AppendPath returns a copy of base with segments appended to its path,
+// separated by exactly one slash regardless of leading or trailing slashes of
+// base and segments. Empty segments are skipped.
+func AppendPath(base *url.URL, segments ...string) *url.URL {
+	ret := *base
+	for _, segment := range segments {
+		segment = strings.Trim(segment, "/")
+		if segment == "" {
+			continue
+		}
+		ret.Path = strings.TrimSuffix(ret.Path, "/") + "/" + segment
+	}
+	ret.RawPath = ""
+	return &ret
+}
+
+// IsWildcardClusterURL returns true if host addresses the wildcard cluster
+// "*" used for cross-workspace requests, like https://host/clusters/*. Only
+// the cluster segment is considered, not resources named "*" further down the
+// path. URLs not addressing a cluster yield an error.
+func IsWildcardClusterURL(host string) (bool, error) {
+	u, err := parseHTTPURL(host)
+	if err != nil {
+		return false, err
+	}
+	if _, _, cluster, _, found := splitClusterPath(u.Path); found && logicalcluster.New(cluster) == logicalcluster.Wildcard {
+		return true, nil
+	}
+	if _, err := ParseClusterURLDetails(host); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// ValidateClusterRequestPath checks that host has the structure of a request
+// against a cluster, <prefix>/clusters/<cluster>/<api-path>, where the API path
+// is empty or below /api or /apis. The workspaces virtual workspace form is not
+// a cluster request and is rejected. Each failure class has its own error:
+// ErrMissingClusterPrefix, ErrInvalidClusterName and ErrInvalidAPIPath.
+func ValidateClusterRequestPath(host string) error {
+	u, err := parseHTTPURL(host)
+	if err != nil {
+		return err
+	}
+	if _, kind, cluster, _, found := splitClusterPath(u.Path); !found || kind != ClustersPath || cluster == "" {
+		return fmt.Errorf("%w: %s", ErrMissingClusterPrefix, host)
+	}
+	parsed, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return err
+	}
+	switch r := parsed.Remainder; {
+	case r == "", r == "/":
+	case r == "/api", strings.HasPrefix(r, "/api/"):
+	case r == "/apis", strings.HasPrefix(r, "/apis/"):
+	default:
+		return fmt.Errorf("%w: %q of %s must be empty or start with /api or /apis", ErrInvalidAPIPath, r, host)
+	}
+	return nil
+}
+
+// ClusterFromRequestURI returns the cluster of a request URI without scheme
+// and host, like /clusters/root:foo/api/v1/pods as found in the requestURI of
+// audit events. Both the /clusters/ and the workspaces virtual workspace forms
+// are understood.
+func ClusterFromRequestURI(uri string) (logicalcluster.Name, error) {
+	u, err := url.ParseRequestURI(uri)
+	if err != nil {
+		return logicalcluster.Name{}, err
+	}
+	_, _, cluster, _, found := splitClusterPath(u.Path)
+	if !found || cluster == "" {
+		return logicalcluster.Name{}, fmt.Errorf("request URI %q is not pointing to a cluster workspace", uri)
+	}
+	clusterName := logicalcluster.New(cluster)
+	if !tenancyhelper.IsValidCluster(clusterName) {
+		return logicalcluster.Name{}, fmt.Errorf("%w: cluster %q of request URI %q", ErrInvalidClusterName, clusterName, uri)
+	}
+	return clusterName, nil
+}
+
+// BuildClusterURL returns the URL of cluster under base in the /clusters/ form,
+// e.g. https://host/clusters/root:foo for base https://host.
+func BuildClusterURL(base *url.URL, cluster logicalcluster.Name) *url.URL {
+	return AppendPath(base, ClustersPath.prefix(), cluster.String())
+}
+
+// BuildWorkspacesVirtualURL returns the URL of cluster under base in the form
+// of the workspaces virtual workspace, e.g.
+// https://host/services/workspaces/root:foo for base https://host.
+func BuildWorkspacesVirtualURL(base *url.URL, cluster logicalcluster.Name) *url.URL {
+	return AppendPath(base, WorkspacesVirtualPath.prefix(), cluster.String())
+}
+
+// ToWorkspacesVirtualURL converts a cluster URL of either form into the form of
+// the workspaces virtual workspace with the same base and cluster. The path
+// following the cluster is dropped.
+func ToWorkspacesVirtualURL(host string) (string, error) {
+	u, cluster, err := ParseClusterURL(host)
+	if err != nil {
+		return "", err
+	}
+	return BuildWorkspacesVirtualURL(u, cluster).String(), nil
+}
+
+// ToClustersURL converts a cluster URL of either form into the /clusters/ form
+// with the same base and cluster. The path following the cluster is dropped.
+func ToClustersURL(host string) (string, error) {
+	u, cluster, err := ParseClusterURL(host)
+	if err != nil {
+		return "", err
+	}
+	return BuildClusterURL(u, cluster).String(), nil
+}
+
+// ValidateKubeconfigServers runs ParseClusterURL over the named server URLs,
+// e.g. those of the contexts of a kubeconfig, and returns the error of every
+// invalid one by name. The returned map is empty if all servers are valid.
+func ValidateKubeconfigServers(servers map[string]string) map[string]error {
+	errs := map[string]error{}
+	for name, server := range servers {
+		if _, _, err := ParseClusterURL(server); err != nil {
+			errs[name] = err
+		}
+	}
+	return errs
+}
+
+// DedupeClusterURLs canonicalizes hosts with CanonicalClusterURL and returns
+// the distinct results in the order they were first seen. Hosts that fail to
+// parse are skipped and their errors are returned as an aggregate.
+func DedupeClusterURLs(hosts []string) ([]string, error) {
+	var deduped []string
+	var errs []error
+	seen := map[string]bool{}
+	for _, host := range hosts {
+		canonical, err := CanonicalClusterURL(host)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if seen[canonical] {
+			continue
+		}
+		seen[canonical] = true
+		deduped = append(deduped, canonical)
+	}
+	return deduped, utilerrors.NewAggregate(errs)
+}
+
+// ParseClusterURLWithAllowedHosts is like ParseClusterURL, but additionally
+// fails with ErrHostNotAllowed if the host of the URL is not one of allowed.
+// Hosts are compared like by CanonicalClusterURL, i.e. case-insensitively and
+// ignoring the default port of the scheme.
+func ParseClusterURLWithAllowedHosts(host string, allowed []string) (*url.URL, logicalcluster.Name, error) {
+	u, cluster, err := ParseClusterURL(host)
+	if err != nil {
+		return nil, logicalcluster.Name{}, err
+	}
+	got := canonicalHost(u.Scheme, u.Host)
+	for _, a := range allowed {
+		if canonicalHost(u.Scheme, a) == got {
+			return u, cluster, nil
+		}
+	}
+	return nil, logicalcluster.Name{}, fmt.Errorf("%w: cluster URL %q", ErrHostNotAllowed, host)
+}
+
+// ParseClusterQualifiedArg parses a command line argument of the form
+// <cluster>|<name> or <cluster>|<namespace>/<name>, as printed by
+// QualifiedObjectName, that targets an object in a specific workspace. The
+// errors are worded for command line users.
+func ParseClusterQualifiedArg(arg string) (cluster logicalcluster.Name, namespace, name string, err error) {
+	cluster, namespace, name, err = tenancyhelper.ParseQualifiedObjectName(arg)
+	if err != nil {
+		return logicalcluster.Name{}, "", "", fmt.Errorf("invalid argument %q: expected <workspace>|<name> or <workspace>|<namespace>/<name>", arg)
+	}
+	if cluster.Empty() {
+		return logicalcluster.Name{}, "", "", fmt.Errorf("invalid argument %q: the workspace before %q must not be empty", arg, "|")
+	}
+	if !tenancyhelper.IsValidCluster(cluster) {
+		return logicalcluster.Name{}, "", "", fmt.Errorf("invalid argument %q: %q is not a valid workspace, e.g. root:org:ws", arg, cluster)
+	}
+	return cluster, namespace, name, nil
+}
+
+// ClusterURLsDiffer compares two cluster URLs, e.g. the servers of two
+// kubeconfig contexts, and reports independently whether the base URL, as
+// compared by SameBaseURL, and whether the cluster changed.
+func ClusterURLsDiffer(oldHost, newHost string) (baseChanged, clusterChanged bool, err error) {
+	oldBase, oldCluster, err := ParseClusterURL(oldHost)
+	if err != nil {
+		return false, false, err
+	}
+	newBase, newCluster, err := ParseClusterURL(newHost)
+	if err != nil {
+		return false, false, err
+	}
+	return !sameBase(oldBase, newBase), oldCluster != newCluster, nil
+}
+
+// ClusterURLPathPrefix returns the path of the base of a cluster URL, i.e.
+// everything before the /clusters/ or workspaces virtual workspace segment,
+// e.g. /apiserver for https://host/apiserver/clusters/root:foo. It is empty if
+// the cluster segment directly follows the host.
+func ClusterURLPathPrefix(host string) (string, error) {
+	parsed, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(parsed.Base.Path, "/"), nil
+}
+
+// IsLegacyClustersForm indicates whether host addresses its cluster in the
+// legacy /clusters/ form rather than through the workspaces virtual
+// workspace, e.g. to warn about the deprecated form. It fails for URLs that
+// are not cluster URLs.
+func IsLegacyClustersForm(host string) (bool, error) {
+	kind, _, err := ParseClusterURLKind(host)
+	if err != nil {
+		return false, err
+	}
+	return kind == ClustersPath, nil
+}
+
+// ClusterFromWatchPath returns the cluster of the path and query of a watch
+// request, e.g. the WebSocket upgrade request /clusters/root:foo/api/v1/pods?watch=true,
+// together with the path following the cluster and the query, i.e.
+// /api/v1/pods?watch=true. Unlike ClusterFromRequestURI, the wildcard cluster
+// "*" of cross-workspace watches is accepted.
+func ClusterFromWatchPath(pathAndQuery string) (cluster logicalcluster.Name, remainder string, err error) {
+	u, err := url.ParseRequestURI(pathAndQuery)
+	if err != nil {
+		return logicalcluster.Name{}, "", err
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return logicalcluster.Name{}, "", fmt.Errorf("watch path %q must not have a scheme or host", pathAndQuery)
+	}
+	_, _, segment, remainder, found := splitClusterPath(u.Path)
+	if !found || segment == "" {
+		return logicalcluster.Name{}, "", fmt.Errorf("watch path %q is not pointing to a cluster workspace", pathAndQuery)
+	}
+	cluster = logicalcluster.New(segment)
+	if cluster != logicalcluster.Wildcard && !tenancyhelper.IsValidCluster(cluster) {
+		return logicalcluster.Name{}, "", fmt.Errorf("%w: cluster %q of watch path %q", ErrInvalidClusterName, cluster, pathAndQuery)
+	}
+	if u.RawQuery != "" {
+		remainder += "?" + u.RawQuery
+	}
+	return cluster, remainder, nil
+}
+
+// BuildClusterWatchURL returns the URL to watch apiPath, e.g. /api/v1/pods, in
+// cluster under base, with opts encoded as query parameters and watch=true.
+// Besides valid clusters, the wildcard cluster "*" is accepted for watches
+// across workspaces.
+func BuildClusterWatchURL(base *url.URL, cluster logicalcluster.Name, apiPath string, opts metav1.ListOptions) (*url.URL, error) {
+	if cluster != logicalcluster.Wildcard && !tenancyhelper.IsValidCluster(cluster) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidClusterName, cluster)
+	}
+	opts.Watch = true
+	query, err := metav1.ParameterCodec.EncodeParameters(&opts, metav1.SchemeGroupVersion)
+	if err != nil {
+		return nil, err
+	}
+	ret := AppendPath(BuildClusterURL(base, cluster), apiPath)
+	ret.RawQuery = query.Encode()
+	return ret, nil
+}
+
+// CleanDuplicatedClusterPrefix collapses a cluster prefix that was applied
+// more than once, e.g. https://host/clusters/root:foo/clusters/root:foo/api
+// becomes https://host/clusters/root:foo/api. The repeated prefix may use
+// either path form. It fails if the repetition addresses a different
+// cluster, as it is then ambiguous which one was meant. URLs without
+// repetition are returned unchanged.
+func CleanDuplicatedClusterPrefix(host string) (string, error) {
+	parsed, err := ParseClusterURLDetails(host)
+	if err != nil {
+		return "", err
+	}
+	remainder := parsed.Remainder
+	changed := false
+	for {
+		basePath, _, cluster, rest, found := splitClusterPath(remainder)
+		if !found || basePath != "" {
 			break
 		}
+		if logicalcluster.New(cluster) != parsed.Cluster {
+			return "", fmt.Errorf("cluster URL %q addresses cluster %q and then %q", host, parsed.Cluster, cluster)
+		}
+		remainder, changed = rest, true
 	}
-	if clusterName.Empty() || !tenancyhelper.IsValidCluster(clusterName) {
-		return nil, logicalcluster.Name{}, fmt.Errorf("current cluster URL %s is not pointing to a cluster workspace", u)
+	if !changed {
+		return host, nil
 	}
+	ret := *parsed.Base
+	ret.Path = parsed.Base.Path + parsed.Kind.prefix() + parsed.Cluster.String() + remainder
+	return ret.String(), nil
+}
 
-	return &ret, clusterName, nil
+// AssertClusterConsistent checks that obj, e.g. as returned by a request to
+// host, lives in the cluster addressed by host. A mismatch indicates a routing
+// bug and the error names both clusters. Invalid URLs and objects with a
+// missing or invalid cluster annotation fail as well.
+func AssertClusterConsistent(host string, obj metav1.Object) error {
+	_, urlCluster, err := ParseClusterURL(host)
+	if err != nil {
+		return err
+	}
+	objCluster, err := tenancyhelper.ClusterFromObject(obj)
+	if err != nil {
+		return err
+	}
+	if urlCluster != objCluster {
+		return fmt.Errorf("%s is in cluster %q, but was served for cluster %q of %s", tenancyhelper.HumanRef(obj), objCluster, urlCluster, host)
+	}
+	return nil
 }
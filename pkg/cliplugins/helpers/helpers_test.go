@@ -17,10 +17,17 @@ limitations under the License.
 package helpers
 
 import (
+	"errors"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 )
 
 func TestParseClusterURL(t *testing.T) {
@@ -46,6 +53,17 @@ func TestParseClusterURL(t *testing.T) {
 		{host: "https://host/services/workspaces/", wantErr: true},
 		{host: "https://host/services/workspaces", wantErr: true},
 		{host: "https://host/abc/clusters/root:foo", url: "https://host/abc", cluster: "root:foo"},
+		{host: "https://host/services/workspaces/root:foo/clusters/abc", url: "https://host", cluster: "root:foo"},
+		{host: "http://host/clusters/root:foo", url: "http://host", cluster: "root:foo"},
+		{host: "https://host:6443/clusters/root:foo", url: "https://host:6443", cluster: "root:foo"},
+		{host: "https://host/clusters/root:foo?watch=true", url: "https://host?watch=true", cluster: "root:foo"},
+		{host: "https://host/clusters/root:foo/api/v1/pods?watch=true&resourceVersion=10", url: "https://host?watch=true&resourceVersion=10", cluster: "root:foo"},
+		{host: "file:///clusters/root", wantErr: true},
+		{host: "file://host/clusters/root", wantErr: true},
+		{host: "gopher://host/clusters/root", wantErr: true},
+		{host: "host/clusters/root", wantErr: true},
+		{host: "/clusters/root", wantErr: true},
+		{host: "https:///clusters/root", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.host, func(t *testing.T) {
@@ -68,3 +86,834 @@ func TestParseClusterURL(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterFromConfigHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		cluster string
+		wantErr bool
+	}{
+		{host: "https://host/clusters/root:foo", cluster: "root:foo"},
+		{host: "https://host/abc/clusters/root:foo/abc", cluster: "root:foo"},
+		{host: "https://host/services/workspaces/root:foo:bar", cluster: "root:foo:bar"},
+		{host: "", wantErr: true},
+		{host: "https://host/foo", wantErr: true},
+		{host: "https://host/clusters/abc:def", wantErr: true},
+		{host: "file:///clusters/root", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := ClusterFromConfigHost(tt.host)
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, logicalcluster.New(tt.cluster), got)
+		})
+	}
+}
+
+func TestParseClusterURLKind(t *testing.T) {
+	tests := []struct {
+		host    string
+		kind    ClusterURLKind
+		cluster string
+		wantErr bool
+	}{
+		{host: "https://host/clusters/root:foo", kind: ClustersPath, cluster: "root:foo"},
+		{host: "https://host/clusters/root:foo/api/v1", kind: ClustersPath, cluster: "root:foo"},
+		{host: "https://host/abc/clusters/root:foo", kind: ClustersPath, cluster: "root:foo"},
+		{host: "https://host/services/workspaces/root:foo", kind: WorkspacesVirtualPath, cluster: "root:foo"},
+		{host: "https://host/services/workspaces/root:foo/abc", kind: WorkspacesVirtualPath, cluster: "root:foo"},
+		{host: "https://host/abc/services/workspaces/root:foo", kind: WorkspacesVirtualPath, cluster: "root:foo"},
+		{host: "https://host/foo", wantErr: true},
+		{host: "https://host/services/workspaces/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			gotKind, gotCluster, err := ParseClusterURLKind(tt.host)
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %v, %q", gotKind, gotCluster)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.kind, gotKind)
+			require.Equal(t, logicalcluster.New(tt.cluster), gotCluster)
+		})
+	}
+}
+
+func TestParseClusterURLKeepsQuery(t *testing.T) {
+	u, cluster, err := ParseClusterURL("https://host/clusters/root:foo?watch=true")
+	require.NoError(t, err)
+	require.Equal(t, logicalcluster.New("root:foo"), cluster)
+	require.Equal(t, "watch=true", u.RawQuery)
+	require.Equal(t, "true", u.Query().Get("watch"))
+}
+
+func TestCanonicalClusterURL(t *testing.T) {
+	tests := []struct {
+		hosts   []string
+		want    string
+		wantErr bool
+	}{
+		{
+			hosts: []string{
+				"https://host/clusters/root:foo",
+				"https://HOST/clusters/root:foo",
+				"https://host:443/clusters/root:foo",
+				"HTTPS://Host:443/clusters/root:foo/api/v1?watch=true",
+				"https://host//clusters/root:foo",
+				"https://host./clusters/root:foo",
+				"https://host.:443/clusters/root:foo",
+			},
+			want: "https://host/clusters/root:foo",
+		},
+		{
+			hosts: []string{
+				"https://host.namespace.svc.cluster.local./clusters/root",
+				"https://host.namespace.svc.cluster.local/clusters/root",
+			},
+			want: "https://host.namespace.svc.cluster.local/clusters/root",
+		},
+		{
+			hosts: []string{
+				"http://host/clusters/root:foo",
+				"http://host:80/clusters/root:foo",
+			},
+			want: "http://host/clusters/root:foo",
+		},
+		{
+			hosts: []string{
+				"https://host:6443/clusters/root:foo",
+				"https://HOST:6443/clusters/root:foo",
+			},
+			want: "https://host:6443/clusters/root:foo",
+		},
+		{
+			hosts: []string{
+				"https://host/abc/clusters/root:foo",
+				"https://host/abc//clusters/root:foo",
+				"https://host/abc/./clusters/root:foo",
+			},
+			want: "https://host/abc/clusters/root:foo",
+		},
+		{
+			hosts: []string{
+				"https://host/services/workspaces/root:foo",
+				"https://host:443/services/workspaces/root:foo/abc",
+			},
+			want: "https://host/services/workspaces/root:foo",
+		},
+		{
+			hosts: []string{
+				"https://[::1]:443/clusters/root:foo",
+				"https://[::1]/clusters/root:foo",
+			},
+			want: "https://[::1]/clusters/root:foo",
+		},
+		{hosts: []string{"https://host/foo"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		for _, host := range tt.hosts {
+			t.Run(host, func(t *testing.T) {
+				got, err := CanonicalClusterURL(host)
+				if tt.wantErr {
+					require.Error(t, err, "instead of error got %q", got)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
+
+func TestParseClusterSubdomainURL(t *testing.T) {
+	tests := []struct {
+		host    string
+		suffix  string
+		url     string
+		cluster string
+		wantErr bool
+	}{
+		{host: "https://root.kcp.example.com", suffix: "kcp.example.com", url: "https://kcp.example.com", cluster: "root"},
+		{host: "https://root-foo.kcp.example.com/api/v1", suffix: "kcp.example.com", url: "https://kcp.example.com", cluster: "root:foo"},
+		{host: "https://root-foo-bar.kcp.example.com:6443/apis", suffix: "kcp.example.com", url: "https://kcp.example.com:6443", cluster: "root:foo:bar"},
+		{host: "https://ROOT-Foo.KCP.example.com", suffix: ".kcp.example.com", url: "https://kcp.example.com", cluster: "root:foo"},
+		{host: "http://system-foo.kcp.example.com", suffix: "kcp.example.com", url: "http://kcp.example.com", cluster: "system:foo"},
+		{host: "https://kcp.example.com/api", suffix: "kcp.example.com", wantErr: true},
+		{host: "https://a.root-foo.kcp.example.com", suffix: "kcp.example.com", wantErr: true},
+		{host: "https://root-foo.other.example.com", suffix: "kcp.example.com", wantErr: true},
+		{host: "https://abc-def.kcp.example.com", suffix: "kcp.example.com", wantErr: true},
+		{host: "https://root--foo.kcp.example.com", suffix: "kcp.example.com", wantErr: true},
+		{host: "file://root-foo.kcp.example.com", suffix: "kcp.example.com", wantErr: true},
+		{host: "https://root-foo.kcp.example.com", suffix: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			gotURL, gotCluster, err := ParseClusterSubdomainURL(tt.host, tt.suffix)
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q, %q", gotURL, gotCluster)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.url, gotURL.String())
+			require.Equal(t, logicalcluster.New(tt.cluster), gotCluster)
+		})
+	}
+}
+
+func TestClusterURLIsRoot(t *testing.T) {
+	tests := []struct {
+		host string
+		root bool
+	}{
+		{host: "https://host/clusters/root", root: true},
+		{host: "https://host/clusters/system", root: true},
+		{host: "https://host/services/workspaces/root", root: true},
+		{host: "https://host/clusters/root:foo", root: false},
+		{host: "https://host/clusters/root:foo:bar", root: false},
+		{host: "https://host/clusters/system:foo", root: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			u, err := ParseClusterURLDetails(tt.host)
+			require.NoError(t, err)
+			require.Equal(t, tt.root, u.IsRoot())
+		})
+	}
+}
+
+func TestReplaceClusterInURL(t *testing.T) {
+	tests := []struct {
+		host       string
+		newCluster string
+		want       string
+		wantErr    bool
+	}{
+		{host: "https://host/clusters/root:foo", newCluster: "root:bar", want: "https://host/clusters/root:bar"},
+		{host: "https://host/clusters/root:foo/api/v1/namespaces/default/pods?watch=true&resourceVersion=5", newCluster: "root:bar:baz", want: "https://host/clusters/root:bar:baz/api/v1/namespaces/default/pods?watch=true&resourceVersion=5"},
+		{host: "https://host/abc/clusters/root:foo/apis", newCluster: "system:bar", want: "https://host/abc/clusters/system:bar/apis"},
+		{host: "https://host/services/workspaces/root:foo/apis/tenancy.kcp.dev/v1beta1/workspaces", newCluster: "root:bar", want: "https://host/services/workspaces/root:bar/apis/tenancy.kcp.dev/v1beta1/workspaces"},
+		{host: "https://host/clusters/root:foo/", newCluster: "root:bar", want: "https://host/clusters/root:bar/"},
+		{host: "https://host/clusters/root:foo", newCluster: "abc:def", wantErr: true},
+		{host: "https://host/foo", newCluster: "root:bar", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := ReplaceClusterInURL(tt.host, logicalcluster.New(tt.newCluster))
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseClusterURLInvalidClusterName(t *testing.T) {
+	tests := []struct {
+		host           string
+		invalidCluster bool
+	}{
+		{host: "https://host/clusters/abc:def", invalidCluster: true},
+		{host: "https://host/clusters/root::def", invalidCluster: true},
+		{host: "https://host/services/workspaces/Root", invalidCluster: true},
+		{host: "https://host/foo", invalidCluster: false},
+		{host: "https://host/clusters/", invalidCluster: false},
+		{host: "file:///clusters/abc:def", invalidCluster: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			_, _, err := ParseClusterURL(tt.host)
+			require.Error(t, err)
+			require.Equal(t, tt.invalidCluster, errors.Is(err, ErrInvalidClusterName), "unexpected error %v", err)
+		})
+	}
+}
+
+func TestSameBaseURL(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    bool
+		wantErr bool
+	}{
+		{a: "https://host/clusters/root:foo", b: "https://host/clusters/root:bar", want: true},
+		{a: "https://host/clusters/root:foo/api/v1", b: "https://host/clusters/system:bar/apis?watch=true", want: true},
+		{a: "https://host/abc/clusters/root:foo", b: "https://host/abc/clusters/root:bar", want: true},
+		{a: "https://host/clusters/root:foo", b: "https://other/clusters/root:foo", want: false},
+		{a: "https://host/clusters/root:foo", b: "https://host:6443/clusters/root:foo", want: false},
+		{a: "https://host/clusters/root:foo", b: "http://host/clusters/root:foo", want: false},
+		{a: "https://host/abc/clusters/root:foo", b: "https://host/clusters/root:foo", want: false},
+		{a: "https://host/foo", b: "https://host/clusters/root:foo", wantErr: true},
+		{a: "https://host/clusters/root:foo", b: "https://host/foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got, err := SameBaseURL(tt.a, tt.b)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateClusterForBase(t *testing.T) {
+	shard, err := url.Parse("https://shard-1:6443/prefix")
+	require.NoError(t, err)
+
+	tests := []struct {
+		host    string
+		wantErr error
+	}{
+		{host: "https://shard-1:6443/prefix/clusters/root:foo"},
+		{host: "https://shard-1:6443/prefix/services/workspaces/root:foo/api"},
+		{host: "https://shard-2:6443/prefix/clusters/root:foo", wantErr: ErrBaseURLMismatch},
+		{host: "https://shard-1/prefix/clusters/root:foo", wantErr: ErrBaseURLMismatch},
+		{host: "http://shard-1:6443/prefix/clusters/root:foo", wantErr: ErrBaseURLMismatch},
+		{host: "https://shard-1:6443/clusters/root:foo", wantErr: ErrBaseURLMismatch},
+		{host: "https://shard-1:6443/prefix/clusters/abc:def", wantErr: ErrInvalidClusterName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := ValidateClusterForBase(tt.host, shard)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	err = ValidateClusterForBase("https://shard-1:6443/prefix/foo", shard)
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrBaseURLMismatch))
+}
+
+func TestParseClusterURLDetailsRemainder(t *testing.T) {
+	tests := []struct {
+		host      string
+		cluster   string
+		remainder string
+	}{
+		{host: "https://host/clusters/root:foo", cluster: "root:foo", remainder: ""},
+		{host: "https://host/clusters/root:foo/", cluster: "root:foo", remainder: "/"},
+		{host: "https://host/clusters/root:foo/healthz", cluster: "root:foo", remainder: "/healthz"},
+		{host: "https://host/clusters/root:foo/readyz?verbose", cluster: "root:foo", remainder: "/readyz"},
+		{host: "https://host/clusters/root:foo/livez", cluster: "root:foo", remainder: "/livez"},
+		{host: "https://host/clusters/root:foo/livez/ping", cluster: "root:foo", remainder: "/livez/ping"},
+		{host: "https://host/services/workspaces/root:foo/healthz", cluster: "root:foo", remainder: "/healthz"},
+		{host: "https://host/clusters/root:foo/api/v1/namespaces", cluster: "root:foo", remainder: "/api/v1/namespaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			u, err := ParseClusterURLDetails(tt.host)
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.cluster), u.Cluster)
+			require.Equal(t, tt.remainder, u.Remainder)
+		})
+	}
+}
+
+func TestAppendPath(t *testing.T) {
+	tests := []struct {
+		base     string
+		segments []string
+		want     string
+	}{
+		{base: "https://host", segments: []string{"apis"}, want: "https://host/apis"},
+		{base: "https://host/", segments: []string{"apis"}, want: "https://host/apis"},
+		{base: "https://host/prefix", segments: []string{"/apis"}, want: "https://host/prefix/apis"},
+		{base: "https://host/prefix/", segments: []string{"/apis/"}, want: "https://host/prefix/apis"},
+		{base: "https://host", segments: []string{"clusters", "root:foo", "apis", "apps/v1"}, want: "https://host/clusters/root:foo/apis/apps/v1"},
+		{base: "https://host", segments: []string{"", "apis", "", "/"}, want: "https://host/apis"},
+		{base: "https://host/prefix", segments: nil, want: "https://host/prefix"},
+		{base: "https://host/prefix?watch=true", segments: []string{"api"}, want: "https://host/prefix/api?watch=true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.base+" "+strings.Join(tt.segments, ","), func(t *testing.T) {
+			base, err := url.Parse(tt.base)
+			require.NoError(t, err)
+			original := base.String()
+
+			got := AppendPath(base, tt.segments...)
+			require.Equal(t, tt.want, got.String())
+			require.Equal(t, original, base.String(), "base must not be modified")
+		})
+	}
+}
+
+func TestIsWildcardClusterURL(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    bool
+		wantErr bool
+	}{
+		{host: "https://host/clusters/*", want: true},
+		{host: "https://host/clusters/*/apis/apis.kcp.dev/v1alpha1/apibindings", want: true},
+		{host: "https://host/abc/clusters/*/api", want: true},
+		{host: "https://host/clusters/root:foo", want: false},
+		{host: "https://host/clusters/root:foo/api/v1/namespaces/*", want: false},
+		{host: "https://host/foo/*", wantErr: true},
+		{host: "https://host/clusters/", wantErr: true},
+		{host: "file:///clusters/*", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := IsWildcardClusterURL(tt.host)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateClusterRequestPath(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr error
+	}{
+		{host: "https://host/clusters/root:foo"},
+		{host: "https://host/clusters/root:foo/"},
+		{host: "https://host/clusters/root:foo/api"},
+		{host: "https://host/clusters/root:foo/api/v1/namespaces/default/pods"},
+		{host: "https://host/clusters/root:foo/apis/apps/v1/deployments?watch=true"},
+		{host: "https://host/prefix/clusters/root:foo/apis"},
+
+		{host: "https://host/foo/api/v1", wantErr: ErrMissingClusterPrefix},
+		{host: "https://host/services/workspaces/root:foo/apis", wantErr: ErrMissingClusterPrefix},
+		{host: "https://host/clusters/", wantErr: ErrMissingClusterPrefix},
+		{host: "https://host/clusters/abc:def/api", wantErr: ErrInvalidClusterName},
+		{host: "https://host/clusters/root:foo/healthz", wantErr: ErrInvalidAPIPath},
+		{host: "https://host/clusters/root:foo/apiserver", wantErr: ErrInvalidAPIPath},
+		{host: "https://host/clusters/root:foo/apisx/v1", wantErr: ErrInvalidAPIPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := ValidateClusterRequestPath(tt.host)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestClusterFromRequestURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		cluster string
+		wantErr bool
+	}{
+		{uri: "/clusters/root:foo/api/v1/pods", cluster: "root:foo"},
+		{uri: "/clusters/root:foo/api/v1/pods?watch=true&labelSelector=a%3Db", cluster: "root:foo"},
+		{uri: "/clusters/root:foo", cluster: "root:foo"},
+		{uri: "/services/workspaces/root:foo:bar/apis/tenancy.kcp.dev/v1beta1/workspaces", cluster: "root:foo:bar"},
+		{uri: "/api/v1/pods", wantErr: true},
+		{uri: "/clusters/", wantErr: true},
+		{uri: "/clusters/abc:def/api", wantErr: true},
+		{uri: "clusters/root:foo", wantErr: true},
+		{uri: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := ClusterFromRequestURI(tt.uri)
+			if tt.wantErr {
+				require.Error(t, err, "instead of error got %q", got)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.cluster), got)
+		})
+	}
+}
+
+func TestConvertClusterURLForms(t *testing.T) {
+	tests := []struct {
+		host       string
+		workspaces string
+		clusters   string
+		wantErr    bool
+	}{
+		{host: "https://host/clusters/root:foo", workspaces: "https://host/services/workspaces/root:foo", clusters: "https://host/clusters/root:foo"},
+		{host: "https://host/prefix/clusters/root:foo/api/v1", workspaces: "https://host/prefix/services/workspaces/root:foo", clusters: "https://host/prefix/clusters/root:foo"},
+		{host: "https://host/services/workspaces/root:foo:bar", workspaces: "https://host/services/workspaces/root:foo:bar", clusters: "https://host/clusters/root:foo:bar"},
+		{host: "https://host/foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			workspaces, err := ToWorkspacesVirtualURL(tt.host)
+			if tt.wantErr {
+				require.Error(t, err)
+				_, err = ToClustersURL(tt.host)
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.workspaces, workspaces)
+
+			clusters, err := ToClustersURL(workspaces)
+			require.NoError(t, err)
+			require.Equal(t, tt.clusters, clusters)
+
+			roundTripped, err := ToClustersURL(tt.host)
+			require.NoError(t, err)
+			require.Equal(t, clusters, roundTripped)
+		})
+	}
+}
+
+func TestValidateKubeconfigServers(t *testing.T) {
+	errs := ValidateKubeconfigServers(map[string]string{
+		"root":      "https://host/clusters/root",
+		"workspace": "https://host/services/workspaces/root:foo",
+		"no-prefix": "https://host/foo",
+		"invalid":   "https://host/clusters/abc:def",
+		"garbage":   "garbage",
+	})
+	require.Len(t, errs, 3)
+	require.Error(t, errs["no-prefix"])
+	require.ErrorIs(t, errs["invalid"], ErrInvalidClusterName)
+	require.Error(t, errs["garbage"])
+
+	require.Empty(t, ValidateKubeconfigServers(map[string]string{"root": "https://host/clusters/root"}))
+	require.Empty(t, ValidateKubeconfigServers(nil))
+}
+
+func TestDedupeClusterURLs(t *testing.T) {
+	deduped, err := DedupeClusterURLs([]string{
+		"https://host/clusters/root:foo",
+		"https://HOST:443/clusters/root:foo/",
+		"https://other/clusters/root:foo",
+		"https://host./clusters/root:foo/api/v1",
+		"https://host/clusters/root:bar",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"https://host/clusters/root:foo",
+		"https://other/clusters/root:foo",
+		"https://host/clusters/root:bar",
+	}, deduped)
+
+	deduped, err = DedupeClusterURLs([]string{
+		"https://host/clusters/root:foo",
+		"garbage",
+		"https://host:443/clusters/root:foo",
+		"https://host/clusters/abc:def",
+	})
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrInvalidClusterName)
+	require.Equal(t, []string{"https://host/clusters/root:foo"}, deduped)
+
+	deduped, err = DedupeClusterURLs(nil)
+	require.NoError(t, err)
+	require.Empty(t, deduped)
+}
+
+func TestParseClusterURLWithAllowedHosts(t *testing.T) {
+	allowed := []string{"kcp.example.com", "other.example.com:6443", "[::1]"}
+	tests := []struct {
+		host       string
+		wantErr    bool
+		notAllowed bool
+	}{
+		{host: "https://kcp.example.com/clusters/root:foo"},
+		{host: "https://kcp.example.com:443/clusters/root:foo"},
+		{host: "https://KCP.example.com./clusters/root:foo"},
+		{host: "https://other.example.com:6443/clusters/root:foo"},
+		{host: "https://[::1]:443/clusters/root"},
+		{host: "https://other.example.com/clusters/root:foo", wantErr: true, notAllowed: true},
+		{host: "https://kcp.example.com:6443/clusters/root:foo", wantErr: true, notAllowed: true},
+		{host: "https://evil.example.com/clusters/root:foo", wantErr: true, notAllowed: true},
+		{host: "https://kcp.example.com/clusters/abc:def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			u, cluster, err := ParseClusterURLWithAllowedHosts(tt.host, allowed)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.notAllowed, errors.Is(err, ErrHostNotAllowed))
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, u)
+			require.False(t, cluster.Empty())
+		})
+	}
+
+	_, _, err := ParseClusterURLWithAllowedHosts("https://kcp.example.com/clusters/root", nil)
+	require.ErrorIs(t, err, ErrHostNotAllowed)
+}
+
+func TestParseClusterQualifiedArg(t *testing.T) {
+	tests := []struct {
+		arg       string
+		cluster   string
+		namespace string
+		name      string
+		wantErr   bool
+	}{
+		{arg: "root:foo|my-configmap", cluster: "root:foo", name: "my-configmap"},
+		{arg: "root:foo|ns/my-configmap", cluster: "root:foo", namespace: "ns", name: "my-configmap"},
+		{arg: "root|name", cluster: "root", name: "name"},
+		{arg: "my-configmap", wantErr: true},
+		{arg: "|my-configmap", wantErr: true},
+		{arg: "root:foo|", wantErr: true},
+		{arg: "root:foo|/name", wantErr: true},
+		{arg: "root:foo|ns/", wantErr: true},
+		{arg: "root:foo|ns/name/extra", wantErr: true},
+		{arg: "abc:def|name", wantErr: true},
+		{arg: "root:Foo|name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			cluster, namespace, name, err := ParseClusterQualifiedArg(tt.arg)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.arg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.cluster), cluster)
+			require.Equal(t, tt.namespace, namespace)
+			require.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestClusterURLsDiffer(t *testing.T) {
+	tests := []struct {
+		name               string
+		oldHost, newHost   string
+		wantBaseChanged    bool
+		wantClusterChanged bool
+		wantErr            bool
+	}{
+		{name: "identical", oldHost: "https://host/clusters/root:foo", newHost: "https://host/clusters/root:foo"},
+		{name: "same base, different cluster", oldHost: "https://host/clusters/root:foo", newHost: "https://host/clusters/root:bar", wantClusterChanged: true},
+		{name: "different base, same cluster", oldHost: "https://host/clusters/root:foo", newHost: "https://other/clusters/root:foo", wantBaseChanged: true},
+		{name: "both changed", oldHost: "https://host/clusters/root:foo", newHost: "https://host/prefix/clusters/root:bar", wantBaseChanged: true, wantClusterChanged: true},
+		{name: "path form and remainder are ignored", oldHost: "https://host/clusters/root:foo/api", newHost: "https://host/services/workspaces/root:foo"},
+		{name: "invalid old", oldHost: "https://host/foo", newHost: "https://host/clusters/root:foo", wantErr: true},
+		{name: "invalid new", oldHost: "https://host/clusters/root:foo", newHost: "https://host/clusters/abc:def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseChanged, clusterChanged, err := ClusterURLsDiffer(tt.oldHost, tt.newHost)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantBaseChanged, baseChanged)
+			require.Equal(t, tt.wantClusterChanged, clusterChanged)
+		})
+	}
+}
+
+func TestClusterURLPathPrefix(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{host: "https://host/apiserver/clusters/root:foo", want: "/apiserver"},
+		{host: "https://host/a/b/clusters/root:foo/api/v1", want: "/a/b"},
+		{host: "https://host/clusters/root:foo", want: ""},
+		{host: "https://host/services/workspaces/root:foo", want: ""},
+		{host: "https://host/apiserver/services/workspaces/root:foo", want: "/apiserver"},
+		{host: "https://host/apiserver", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := ClusterURLPathPrefix(tt.host)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClusterURLPrefixesAreReserved(t *testing.T) {
+	reserved := tenancyhelper.ReservedPathSegments()
+	kinds := map[ClusterURLKind]bool{}
+	for _, p := range clusterURLPrefixes {
+		for _, segment := range strings.Split(strings.Trim(p.prefix, "/"), "/") {
+			require.Contains(t, reserved, segment, "segment %q of the %s prefix %q", segment, p.kind, p.prefix)
+		}
+		kinds[p.kind] = true
+	}
+	require.Equal(t, map[ClusterURLKind]bool{ClustersPath: true, WorkspacesVirtualPath: true}, kinds, "every cluster URL kind needs a prefix derived from the reserved path segments")
+}
+
+func TestNewClusterURLPrefixes(t *testing.T) {
+	require.Empty(t, newClusterURLPrefixes([]string{tenancyhelper.ShardsPathSegment}))
+	require.Equal(t, []clusterURLPrefix{{prefix: "/clusters/", kind: ClustersPath}}, newClusterURLPrefixes([]string{tenancyhelper.ClustersPathSegment}))
+}
+
+func TestIsLegacyClustersForm(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    bool
+		wantErr bool
+	}{
+		{host: "https://host/clusters/root:foo", want: true},
+		{host: "https://host/prefix/clusters/root:foo/api/v1", want: true},
+		{host: "https://host/services/workspaces/root:foo", want: false},
+		{host: "https://host/services/workspaces/root:foo/clusters/bar", want: false},
+		{host: "https://host/api/v1", wantErr: true},
+		{host: "https://host/clusters/abc:def", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := IsLegacyClustersForm(tt.host)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClusterFromWatchPath(t *testing.T) {
+	tests := []struct {
+		path      string
+		cluster   string
+		remainder string
+		wantErr   bool
+	}{
+		{path: "/clusters/root:foo/api/v1/pods?watch=true", cluster: "root:foo", remainder: "/api/v1/pods?watch=true"},
+		{path: "/clusters/root:foo/api/v1/pods?watch=true&resourceVersion=10", cluster: "root:foo", remainder: "/api/v1/pods?watch=true&resourceVersion=10"},
+		{path: "/clusters/root:foo/api/v1/pods", cluster: "root:foo", remainder: "/api/v1/pods"},
+		{path: "/clusters/root:foo?watch=true", cluster: "root:foo", remainder: "?watch=true"},
+		{path: "/clusters/root:foo", cluster: "root:foo"},
+		{path: "/clusters/*/apis/apps/v1/deployments?watch=1", cluster: "*", remainder: "/apis/apps/v1/deployments?watch=1"},
+		{path: "/services/workspaces/root:foo/api/v1/pods?watch=true", cluster: "root:foo", remainder: "/api/v1/pods?watch=true"},
+		{path: "/prefix/clusters/root/api", cluster: "root", remainder: "/api"},
+		{path: "/api/v1/pods?watch=true", wantErr: true},
+		{path: "/clusters/?watch=true", wantErr: true},
+		{path: "/clusters/abc:def/api", wantErr: true},
+		{path: "clusters/root:foo/api", wantErr: true},
+		{path: "https://host/clusters/root:foo/api", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			cluster, remainder, err := ClusterFromWatchPath(tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, logicalcluster.New(tt.cluster), cluster)
+			require.Equal(t, tt.remainder, remainder)
+		})
+	}
+}
+
+func TestBuildClusterWatchURL(t *testing.T) {
+	base, err := url.Parse("https://host/prefix")
+	require.NoError(t, err)
+
+	u, err := BuildClusterWatchURL(base, logicalcluster.New("root:foo"), "/api/v1/pods", metav1.ListOptions{
+		LabelSelector:   "app in (a,b),tier!=db",
+		ResourceVersion: "10",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https", u.Scheme)
+	require.Equal(t, "host", u.Host)
+	require.Equal(t, "/prefix/clusters/root:foo/api/v1/pods", u.Path)
+	require.Contains(t, u.RawQuery, "watch=true")
+	require.Contains(t, u.RawQuery, "labelSelector=app+in+%28a%2Cb%29%2Ctier%21%3Ddb")
+	query := u.Query()
+	require.Equal(t, "true", query.Get("watch"))
+	require.Equal(t, "10", query.Get("resourceVersion"))
+	require.Equal(t, "app in (a,b),tier!=db", query.Get("labelSelector"))
+	require.Equal(t, "https://host/prefix", base.String(), "base must not be modified")
+
+	u, err = BuildClusterWatchURL(base, logicalcluster.Wildcard, "apis/apps/v1/deployments", metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "/prefix/clusters/*/apis/apps/v1/deployments", u.Path)
+	require.Equal(t, "watch=true", u.RawQuery)
+
+	_, err = BuildClusterWatchURL(base, logicalcluster.New("abc:def"), "/api/v1/pods", metav1.ListOptions{})
+	require.ErrorIs(t, err, ErrInvalidClusterName)
+}
+
+func TestCleanDuplicatedClusterPrefix(t *testing.T) {
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{host: "https://host/clusters/root:foo/clusters/root:foo", want: "https://host/clusters/root:foo"},
+		{host: "https://host/clusters/root:foo/clusters/root:foo/api/v1?watch=true", want: "https://host/clusters/root:foo/api/v1?watch=true"},
+		{host: "https://host/clusters/root:foo/clusters/root:foo/clusters/root:foo/api", want: "https://host/clusters/root:foo/api"},
+		{host: "https://host/prefix/clusters/root:foo/services/workspaces/root:foo/api", want: "https://host/prefix/clusters/root:foo/api"},
+		{host: "https://host/clusters/root:foo", want: "https://host/clusters/root:foo"},
+		{host: "https://HOST:443/clusters/root:foo/api/v1?watch=true", want: "https://HOST:443/clusters/root:foo/api/v1?watch=true"},
+		{host: "https://host/clusters/root:foo/api/v1/namespaces/clusters/configmaps", want: "https://host/clusters/root:foo/api/v1/namespaces/clusters/configmaps"},
+		{host: "https://host/clusters/root:foo/clusters/root:bar", wantErr: true},
+		{host: "https://host/foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, err := CleanDuplicatedClusterPrefix(tt.host)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAssertClusterConsistent(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		cluster string
+		wantErr bool
+	}{
+		{name: "matching", host: "https://host/clusters/root:foo/api/v1", cluster: "root:foo"},
+		{name: "matching workspaces form", host: "https://host/services/workspaces/root:foo", cluster: "root:foo"},
+		{name: "mismatching", host: "https://host/clusters/root:foo", cluster: "root:bar", wantErr: true},
+		{name: "missing annotation", host: "https://host/clusters/root:foo", wantErr: true},
+		{name: "invalid annotation", host: "https://host/clusters/root:foo", cluster: "abc:def", wantErr: true},
+		{name: "invalid URL", host: "https://host/foo", cluster: "root:foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Name: "cm", Namespace: "ns"}
+			if tt.cluster != "" {
+				obj.Annotations = map[string]string{logicalcluster.AnnotationKey: tt.cluster}
+			}
+			err := AssertClusterConsistent(tt.host, obj)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	err := AssertClusterConsistent("https://host/clusters/root:foo", &metav1.ObjectMeta{Name: "cm", Annotations: map[string]string{logicalcluster.AnnotationKey: "root:bar"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"root:foo"`)
+	require.Contains(t, err.Error(), `"root:bar"`)
+}
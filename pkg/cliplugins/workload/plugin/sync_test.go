@@ -565,6 +565,22 @@ func TestGetGroupMappings(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "multiple types with group",
+			input: []string{
+				"deployments.apps",
+				"statefulsets.apps",
+			},
+			expected: []groupMapping{
+				{
+					APIGroup: "apps",
+					Resources: []string{
+						"deployments",
+						"statefulsets",
+					},
+				},
+			},
+		},
 		{
 			name: "multiple types",
 			input: []string{
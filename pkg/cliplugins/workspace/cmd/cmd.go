@@ -61,6 +61,12 @@ var (
 
 	# create a context with the current workspace, named context-name
 	%[1]s workspace create-context context-name
+
+	# show the tree of workspaces below the current workspace, with their types and phases
+	%[1]s workspace tree
+
+	# restore a deleted workspace of the current workspace that is still in the Trashed phase
+	%[1]s workspace restore my-workspace
 `
 )
 
@@ -181,8 +187,8 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 
 	treeCmdOpts := plugin.NewTreeOptions(streams)
 	treeCmd := &cobra.Command{
-		Use:          "tree",
-		Short:        "Print the current workspace tree.",
+		Use:          "tree [--full] [--chunk-size=<n>]",
+		Short:        "Print the tree of workspaces below the current workspace, with their types and phases.",
 		Example:      "kcp workspace tree",
 		SilenceUsage: true,
 		RunE: func(c *cobra.Command, args []string) error {
@@ -200,10 +206,30 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	}
 	treeCmdOpts.BindFlags(treeCmd)
 
+	restoreWorkspaceOpts := plugin.NewRestoreWorkspaceOptions(streams)
+	restoreCmd := &cobra.Command{
+		Use:          "restore <workspace>",
+		Short:        "Restores a deleted workspace that is still in the Trashed phase",
+		Example:      "kcp workspace restore <workspace name>",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := restoreWorkspaceOpts.Complete(args); err != nil {
+				return err
+			}
+			if err := restoreWorkspaceOpts.Validate(); err != nil {
+				return err
+			}
+			return restoreWorkspaceOpts.Run(c.Context())
+		},
+	}
+	restoreWorkspaceOpts.BindFlags(restoreCmd)
+
 	cmd.AddCommand(useCmd)
 	cmd.AddCommand(treeCmd)
 	cmd.AddCommand(currentCmd)
 	cmd.AddCommand(createCmd)
 	cmd.AddCommand(createContextCmd)
+	cmd.AddCommand(restoreCmd)
 	return cmd, nil
 }
@@ -18,11 +18,13 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/rest"
@@ -225,7 +228,7 @@ func (o *UseWorkspaceOptions) Run(ctx context.Context) error {
 
 	default:
 		cluster := logicalcluster.New(o.Name)
-		if strings.Contains(o.Name, ":") && !cluster.HasPrefix(logicalcluster.New("system")) &&
+		if strings.Contains(o.Name, ":") && !cluster.HasPrefix(tenancyv1alpha1.SystemCluster) &&
 			!cluster.HasPrefix(tenancyv1alpha1.RootCluster) {
 			return fmt.Errorf("invalid workspace name format: %s", o.Name)
 		}
@@ -331,7 +334,7 @@ func (o *UseWorkspaceOptions) Run(ctx context.Context) error {
 
 // getAPIBindings retrieves APIBindings within the workspace
 func getAPIBindings(ctx context.Context, kcpClusterClient kcpclient.ClusterInterface, host string) ([]apisv1alpha1.APIBinding, error) {
-	_, clusterName, err := pluginhelpers.ParseClusterURL(host)
+	clusterName, err := pluginhelpers.ClusterFromConfigHost(host)
 	if err != nil {
 		return nil, err
 	}
@@ -402,7 +405,7 @@ func (o *CurrentWorkspaceOptions) Run(ctx context.Context) error {
 type shortWorkspaceOutput bool
 
 func currentWorkspace(out io.Writer, host string, shortWorkspaceOutput shortWorkspaceOutput, workspaceType *tenancyv1alpha1.ClusterWorkspaceTypeReference) error {
-	_, clusterName, err := pluginhelpers.ParseClusterURL(host)
+	clusterName, err := pluginhelpers.ClusterFromConfigHost(host)
 	if err != nil {
 		if shortWorkspaceOutput {
 			return nil
@@ -492,7 +495,7 @@ func (o *CreateWorkspaceOptions) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	_, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	currentClusterName, err := pluginhelpers.ClusterFromConfigHost(config.Host)
 	if err != nil {
 		return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
 	}
@@ -690,7 +693,7 @@ func (o *CreateContextOptions) Run(ctx context.Context) error {
 	if !ok {
 		return fmt.Errorf("current cluster %q is not found in kubeconfig", currentContext.Cluster)
 	}
-	_, currentClusterName, err := pluginhelpers.ParseClusterURL(currentCluster.Server)
+	currentClusterName, err := pluginhelpers.ClusterFromConfigHost(currentCluster.Server)
 	if err != nil {
 		return fmt.Errorf("current URL %q does not point to cluster workspace", currentCluster.Server)
 	}
@@ -751,13 +754,17 @@ type TreeOptions struct {
 
 	Full bool
 
+	// ChunkSize is the number of workspaces listed per request. 0 means no chunking.
+	ChunkSize int64
+
 	kcpClusterClient kcpclient.ClusterInterface
 }
 
-// NewShowWorkspaceTreeOptions returns a new ShowWorkspaceTreeOptions.
+// NewTreeOptions returns a new TreeOptions.
 func NewTreeOptions(streams genericclioptions.IOStreams) *TreeOptions {
 	return &TreeOptions{
-		Options: base.NewOptions(streams),
+		Options:   base.NewOptions(streams),
+		ChunkSize: 500,
 	}
 }
 
@@ -765,6 +772,15 @@ func NewTreeOptions(streams genericclioptions.IOStreams) *TreeOptions {
 func (o *TreeOptions) BindFlags(cmd *cobra.Command) {
 	o.Options.BindFlags(cmd)
 	cmd.Flags().BoolVarP(&o.Full, "full", "f", o.Full, "Show full workspaces names")
+	cmd.Flags().Int64Var(&o.ChunkSize, "chunk-size", o.ChunkSize, "Return large lists of workspaces in chunks rather than all at once. Pass 0 to disable.")
+}
+
+// Validate validates the TreeOptions are complete and usable.
+func (o *TreeOptions) Validate() error {
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("--chunk-size must not be negative")
+	}
+	return o.Options.Validate()
 }
 
 // Complete ensures all dynamically populated fields are initialized.
@@ -782,52 +798,187 @@ func (o *TreeOptions) Complete() error {
 	return nil
 }
 
-// Run outputs the current workspace.
+// Run outputs the workspace tree under the current workspace.
 func (o *TreeOptions) Run(ctx context.Context) error {
 	config, err := o.ClientConfig.ClientConfig()
 	if err != nil {
 		return err
 	}
-	_, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	currentClusterName, err := pluginhelpers.ClusterFromConfigHost(config.Host)
 	if err != nil {
 		return fmt.Errorf("current config context URL %q does not point to workspace", config.Host)
 	}
 
 	tree := treeprint.New()
-	err = o.populateBranch(ctx, tree, currentClusterName)
-	if err != nil {
+	var root treeprint.Tree
+	if o.Full {
+		root = tree.AddBranch(currentClusterName.String())
+	} else {
+		root = tree.AddBranch(currentClusterName.Base())
+	}
+	if err := o.populateBranch(ctx, root, currentClusterName); err != nil {
 		return err
 	}
 
-	fmt.Println(tree.String())
+	fmt.Fprint(o.Out, tree.String())
 	return nil
 }
 
+// populateBranch adds the child workspaces of the given workspace to the tree, with their type and phase.
+// Workspaces that are not ready yet are shown without children.
 func (o *TreeOptions) populateBranch(ctx context.Context, tree treeprint.Tree, name logicalcluster.Name) error {
-	results, err := o.kcpClusterClient.Cluster(name).TenancyV1beta1().Workspaces().List(ctx, metav1.ListOptions{})
+	workspaces, err := o.listWorkspaces(ctx, name)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
 			return nil
 		}
 		return err
 	}
 
-	var b treeprint.Tree
-	if o.Full {
-		b = tree.AddBranch(name.String())
-	} else {
-		b = tree.AddBranch(name.Base())
-	}
+	for _, workspace := range workspaces {
+		childName := name.Join(workspace.Name)
+		label := workspace.Name
+		if o.Full {
+			label = childName.String()
+		}
+		label = fmt.Sprintf("%s (%s, %s)", label, workspaceTreeType(workspace), workspaceTreePhase(workspace))
 
-	for _, workspace := range results.Items {
-		_, currentClusterName, err := pluginhelpers.ParseClusterURL(workspace.Status.URL)
-		if err != nil {
-			return fmt.Errorf("current config context URL %q does not point to workspace", workspace.Status.URL)
+		if workspace.Status.URL == "" {
+			tree.AddNode(label)
+			continue
 		}
-		err = o.populateBranch(ctx, b, currentClusterName)
+		childClusterName, err := pluginhelpers.ClusterFromConfigHost(workspace.Status.URL)
 		if err != nil {
+			return fmt.Errorf("workspace URL %q does not point to workspace", workspace.Status.URL)
+		}
+		if err := o.populateBranch(ctx, tree.AddBranch(label), childClusterName); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// listWorkspaces lists the workspaces in the given workspace in chunks of ChunkSize.
+func (o *TreeOptions) listWorkspaces(ctx context.Context, name logicalcluster.Name) ([]tenancyv1beta1.Workspace, error) {
+	var workspaces []tenancyv1beta1.Workspace
+	opts := metav1.ListOptions{Limit: o.ChunkSize}
+	for {
+		results, err := o.kcpClusterClient.Cluster(name).TenancyV1beta1().Workspaces().List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, results.Items...)
+		if results.Continue == "" {
+			break
+		}
+		opts.Continue = results.Continue
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Name < workspaces[j].Name
+	})
+	return workspaces, nil
+}
+
+func workspaceTreeType(workspace tenancyv1beta1.Workspace) string {
+	if workspace.Spec.Type.Path == "" {
+		return string(workspace.Spec.Type.Name)
+	}
+	return workspace.Spec.Type.String()
+}
+
+func workspaceTreePhase(workspace tenancyv1beta1.Workspace) string {
+	if workspace.Status.Phase == "" {
+		return "Unknown"
+	}
+	return string(workspace.Status.Phase)
+}
+
+// RestoreWorkspaceOptions contains options for restoring a trashed workspace.
+type RestoreWorkspaceOptions struct {
+	*base.Options
+
+	// Name is the name of the trashed workspace to restore.
+	Name string
+
+	kcpClusterClient kcpclient.ClusterInterface
+}
+
+// NewRestoreWorkspaceOptions returns a new RestoreWorkspaceOptions.
+func NewRestoreWorkspaceOptions(streams genericclioptions.IOStreams) *RestoreWorkspaceOptions {
+	return &RestoreWorkspaceOptions{
+		Options: base.NewOptions(streams),
+	}
+}
+
+// BindFlags binds fields to cmd's flagset.
+func (o *RestoreWorkspaceOptions) BindFlags(cmd *cobra.Command) {
+	o.Options.BindFlags(cmd)
+}
+
+// Complete ensures all dynamically populated fields are initialized.
+func (o *RestoreWorkspaceOptions) Complete(args []string) error {
+	if err := o.Options.Complete(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+
+	kcpClusterClient, err := newKCPClusterClient(o.ClientConfig)
+	if err != nil {
+		return err
+	}
+	o.kcpClusterClient = kcpClusterClient
+
+	return nil
+}
+
+// Validate validates the RestoreWorkspaceOptions are complete and usable.
+func (o *RestoreWorkspaceOptions) Validate() error {
+	if o.Name == "" {
+		return errors.New("workspace name is required")
+	}
+	return o.Options.Validate()
+}
+
+// Run requests the restoration of a trashed workspace in the current workspace.
+func (o *RestoreWorkspaceOptions) Run(ctx context.Context) error {
+	config, err := o.ClientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	currentClusterName, err := pluginhelpers.ClusterFromConfigHost(config.Host)
+	if err != nil {
+		return fmt.Errorf("current config context URL %q does not point to workspace", config.Host)
+	}
+
+	clusterWorkspaces := o.kcpClusterClient.Cluster(currentClusterName).TenancyV1alpha1().ClusterWorkspaces()
+	ws, err := clusterWorkspaces.Get(ctx, o.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("workspace %q not found, it might have been purged already", o.Name)
+	} else if err != nil {
+		return err
+	}
+	if ws.DeletionTimestamp.IsZero() || ws.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseTrashed {
+		return fmt.Errorf("workspace %q is not trashed", o.Name)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				tenancyv1alpha1.ExperimentalClusterWorkspaceRestoreAnnotationKey: "true",
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := clusterWorkspaces.Patch(ctx, o.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(o.Out, "Restoring workspace %q.\n", o.Name)
+	return err
+}
@@ -1543,6 +1543,184 @@ func TestCreateContext(t *testing.T) {
 	}
 }
 
+func TestTree(t *testing.T) {
+	workspace := func(name string, clusterName logicalcluster.Name, phase tenancyv1alpha1.ClusterWorkspacePhaseType, ready bool) *tenancyv1beta1.Workspace {
+		ws := &tenancyv1beta1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{logicalcluster.AnnotationKey: clusterName.String()},
+			},
+			Spec: tenancyv1beta1.WorkspaceSpec{
+				Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{Name: "universal", Path: "root"},
+			},
+			Status: tenancyv1beta1.WorkspaceStatus{Phase: phase},
+		}
+		if ready {
+			ws.Status.URL = fmt.Sprintf("https://test%s", clusterName.Join(name).Path())
+		}
+		return ws
+	}
+	org := logicalcluster.New("root:org")
+
+	tests := []struct {
+		name       string
+		full       bool
+		paginate   bool
+		forbidden  bool
+		wantStdout string
+	}{
+		{
+			name: "types and phases",
+			wantStdout: `.
+└── org
+    ├── a (root:universal, Ready)
+    │   └── c (root:universal, Ready)
+    └── b (root:universal, Scheduling)
+`,
+		},
+		{
+			name: "full names",
+			full: true,
+			wantStdout: `.
+└── root:org
+    ├── root:org:a (root:universal, Ready)
+    │   └── root:org:a:c (root:universal, Ready)
+    └── root:org:b (root:universal, Scheduling)
+`,
+		},
+		{
+			name:     "paginated",
+			paginate: true,
+			wantStdout: `.
+└── org
+    ├── a (root:universal, Ready)
+    │   └── c (root:universal, Ready)
+    └── b (root:universal, Scheduling)
+`,
+		},
+		{
+			name:      "forbidden workspaces are skipped",
+			forbidden: true,
+			wantStdout: `.
+└── org
+    ├── a (root:universal, Ready)
+    └── b (root:universal, Scheduling)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := workspace("a", org, tenancyv1alpha1.ClusterWorkspacePhaseReady, true), workspace("b", org, tenancyv1alpha1.ClusterWorkspacePhaseScheduling, false)
+			orgClient := fakeclient.NewSimpleClientset(b, a)
+			if tt.paginate {
+				pages := [][]tenancyv1beta1.Workspace{{*b}, {*a}}
+				orgClient.PrependReactor("list", "workspaces", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+					list := &tenancyv1beta1.WorkspaceList{Items: pages[0]}
+					if pages = pages[1:]; len(pages) > 0 {
+						list.Continue = "next"
+					}
+					return true, list, nil
+				})
+			}
+			aClient := fakeclient.NewSimpleClientset(workspace("c", org.Join("a"), tenancyv1alpha1.ClusterWorkspacePhaseReady, true))
+			if tt.forbidden {
+				aClient.PrependReactor("list", "workspaces", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+					return true, nil, errors.NewForbidden(tenancyv1beta1.Resource("workspaces"), "", fmt.Errorf("not allowed"))
+				})
+			}
+
+			streams, _, stdout, _ := genericclioptions.NewTestIOStreams()
+			opts := NewTreeOptions(streams)
+			opts.Full = tt.full
+			opts.ChunkSize = 1
+			opts.kcpClusterClient = fakeTenancyClient{
+				t: t,
+				clients: map[logicalcluster.Name]*fakeclient.Clientset{
+					org:                     orgClient,
+					org.Join("a"):           aClient,
+					org.Join("a").Join("c"): fakeclient.NewSimpleClientset(),
+				},
+			}
+			opts.ClientConfig = clientcmd.NewDefaultClientConfig(clientcmdapi.Config{CurrentContext: "test",
+				Contexts:  map[string]*clientcmdapi.Context{"test": {Cluster: "test", AuthInfo: "test"}},
+				Clusters:  map[string]*clientcmdapi.Cluster{"test": {Server: "https://test/clusters/root:org"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			}, nil)
+
+			require.NoError(t, opts.Run(context.Background()))
+			// treeprint indents with non-breaking spaces
+			require.Equal(t, tt.wantStdout, strings.ReplaceAll(stdout.String(), "\u00a0", " "))
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	deleted := metav1.Now()
+	tests := []struct {
+		name       string
+		existing   *tenancyv1alpha1.ClusterWorkspace
+		wantStdout string
+		wantErr    string
+	}{
+		{
+			name: "trashed workspace is restored",
+			existing: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "ws", DeletionTimestamp: &deleted, Finalizers: []string{"tenancy.kcp.dev/workspace-finalizer"}},
+				Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Phase: tenancyv1alpha1.ClusterWorkspacePhaseTrashed},
+			},
+			wantStdout: "Restoring workspace \"ws\".\n",
+		},
+		{
+			name: "ready workspace is not restored",
+			existing: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "ws"},
+				Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady},
+			},
+			wantErr: "workspace \"ws\" is not trashed",
+		},
+		{
+			name:    "purged workspace is not found",
+			wantErr: "workspace \"ws\" not found, it might have been purged already",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []runtime.Object
+			if tt.existing != nil {
+				objects = append(objects, tt.existing)
+			}
+			client := fakeclient.NewSimpleClientset(objects...)
+
+			streams, _, stdout, _ := genericclioptions.NewTestIOStreams()
+			opts := NewRestoreWorkspaceOptions(streams)
+			opts.Name = "ws"
+			opts.kcpClusterClient = fakeTenancyClient{
+				t: t,
+				clients: map[logicalcluster.Name]*fakeclient.Clientset{
+					logicalcluster.New("root:org"): client,
+				},
+			}
+			opts.ClientConfig = clientcmd.NewDefaultClientConfig(clientcmdapi.Config{CurrentContext: "test",
+				Contexts:  map[string]*clientcmdapi.Context{"test": {Cluster: "test", AuthInfo: "test"}},
+				Clusters:  map[string]*clientcmdapi.Cluster{"test": {Server: "https://test/clusters/root:org"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			}, nil)
+
+			err := opts.Run(context.Background())
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStdout, stdout.String())
+
+			ws, err := client.TenancyV1alpha1().ClusterWorkspaces().Get(context.Background(), "ws", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Equal(t, "true", ws.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceRestoreAnnotationKey])
+		})
+	}
+}
+
 func parseURLOrDie(host string) *url.URL {
 	u, err := url.Parse(host)
 	if err != nil {
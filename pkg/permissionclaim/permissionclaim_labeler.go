@@ -22,7 +22,12 @@ import (
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/tools/clusters"
 	"k8s.io/klog/v2"
 
@@ -38,10 +43,11 @@ import (
 type Labeler struct {
 	listAPIBindingsAcceptingClaimedGroupResource func(clusterName logicalcluster.Name, groupResource schema.GroupResource) ([]*apisv1alpha1.APIBinding, error)
 	getAPIBinding                                func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIBinding, error)
+	getNamespace                                 func(clusterName logicalcluster.Name, name string) (*corev1.Namespace, error)
 }
 
 // NewLabeler returns a new Labeler.
-func NewLabeler(apiBindingInformer apisinformers.APIBindingInformer) *Labeler {
+func NewLabeler(apiBindingInformer apisinformers.APIBindingInformer, namespaceInformer coreinformers.NamespaceInformer) *Labeler {
 	return &Labeler{
 		listAPIBindingsAcceptingClaimedGroupResource: func(clusterName logicalcluster.Name, groupResource schema.GroupResource) ([]*apisv1alpha1.APIBinding, error) {
 			indexKey := indexers.ClusterAndGroupResourceValue(clusterName, groupResource)
@@ -52,13 +58,18 @@ func NewLabeler(apiBindingInformer apisinformers.APIBindingInformer) *Labeler {
 			key := clusters.ToClusterAwareKey(clusterName, name)
 			return apiBindingInformer.Lister().Get(key)
 		},
+
+		getNamespace: func(clusterName logicalcluster.Name, name string) (*corev1.Namespace, error) {
+			return namespaceInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
 	}
 }
 
 // LabelsFor returns all the applicable labels for the cluster-group-resource relating to permission claims. This is
 // the intersection of (1) all APIBindings in the cluster that have accepted claims for the group-resource with (2)
-// associated APIExports that are claiming group-resource.
-func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, groupResource schema.GroupResource, resourceName string) (map[string]string, error) {
+// associated APIExports that are claiming group-resource. Claims restricted to namespaces only apply to objects
+// in matching namespaces, i.e. never to cluster-scoped objects other than the matching namespaces themselves.
+func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, groupResource schema.GroupResource, resourceNamespace, resourceName string) (map[string]string, error) {
 	labels := map[string]string{}
 
 	bindings, err := l.listAPIBindingsAcceptingClaimedGroupResource(cluster, groupResource)
@@ -82,6 +93,21 @@ func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, gr
 			if claim.Group != groupResource.Group || claim.Resource != groupResource.Resource {
 				continue
 			}
+			if !isAccepted(binding, claim) {
+				// the claim might have been changed by the export owner, and the new one has not been accepted yet
+				logger.V(4).Info("skipping permission claim because it is not accepted", "claim", claim.String())
+				continue
+			}
+			if claim.IsNamespaceRestricted() {
+				inNamespace, err := l.inClaimedNamespace(cluster, claim, groupResource, resourceNamespace, resourceName)
+				if err != nil {
+					logger.Error(err, "error checking namespace restriction of permission claim", "claim", claim.String())
+					continue
+				}
+				if !inNamespace {
+					continue
+				}
+			}
 
 			k, v, err := permissionclaims.ToLabelKeyAndValue(logicalcluster.New(boundAPIExportWorkspace.Path), boundAPIExportWorkspace.ExportName, claim)
 			if err != nil {
@@ -114,3 +140,47 @@ func (l *Labeler) LabelsFor(ctx context.Context, cluster logicalcluster.Name, gr
 
 	return labels, nil
 }
+
+// isAccepted returns true if the binding accepts exactly the given claim, including its namespace restriction.
+func isAccepted(binding *apisv1alpha1.APIBinding, claim apisv1alpha1.PermissionClaim) bool {
+	for _, accepted := range binding.Spec.PermissionClaims {
+		if accepted.State == apisv1alpha1.ClaimAccepted && accepted.PermissionClaim.Equal(claim) {
+			return true
+		}
+	}
+	return false
+}
+
+// inClaimedNamespace returns true if the object is in one of the namespaces the claim is restricted to.
+// For namespaces, the object itself is the namespace to check.
+func (l *Labeler) inClaimedNamespace(cluster logicalcluster.Name, claim apisv1alpha1.PermissionClaim, groupResource schema.GroupResource, resourceNamespace, resourceName string) (bool, error) {
+	namespace := resourceNamespace
+	if groupResource == corev1.Resource("namespaces") {
+		namespace = resourceName
+	}
+	if namespace == "" {
+		return false, nil
+	}
+
+	for _, ns := range claim.Namespaces {
+		if ns == namespace {
+			return true, nil
+		}
+	}
+	if claim.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(claim.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	ns, err := l.getNamespace(cluster, namespace)
+	if apierrors.IsNotFound(err) {
+		// the objects are relabeled when the namespace shows up
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permissionclaim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1/permissionclaims"
+)
+
+func TestLabelsFor(t *testing.T) {
+	secrets := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}}
+	inNamespaces := func(namespaces ...string) apisv1alpha1.PermissionClaim {
+		claim := secrets
+		claim.Namespaces = namespaces
+		return claim
+	}
+	withSelector := func(matchLabels map[string]string) apisv1alpha1.PermissionClaim {
+		claim := secrets
+		claim.NamespaceSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		return claim
+	}
+	readOnly := secrets
+	readOnly.Verbs = []string{"get", "list", "watch"}
+	namespaceClaim := apisv1alpha1.PermissionClaim{
+		GroupResource: apisv1alpha1.GroupResource{Resource: "namespaces"},
+		Namespaces:    []string{"shared"},
+	}
+	namespaces := map[string]*corev1.Namespace{
+		"shared": {ObjectMeta: metav1.ObjectMeta{Name: "shared", Labels: map[string]string{"team": "a"}}},
+		"other":  {ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"team": "b"}}},
+	}
+
+	tests := []struct {
+		name          string
+		exported      apisv1alpha1.PermissionClaim
+		accepted      apisv1alpha1.PermissionClaim
+		groupResource schema.GroupResource
+		namespace     string
+		resourceName  string
+		wantLabel     bool
+	}{
+		{name: "unrestricted claim", exported: secrets, accepted: secrets, namespace: "other", resourceName: "s", wantLabel: true},
+		{name: "unrestricted claim on cluster-scoped object", exported: secrets, accepted: secrets, resourceName: "s", wantLabel: true},
+		{name: "exported claim differs from accepted one", exported: inNamespaces("shared", "other"), accepted: inNamespaces("shared"), namespace: "shared", resourceName: "s"},
+		{name: "exported verbs differ from accepted ones", exported: secrets, accepted: readOnly, namespace: "other", resourceName: "s"},
+		{name: "verb restricted claim", exported: readOnly, accepted: readOnly, namespace: "other", resourceName: "s", wantLabel: true},
+		{name: "namespaces are compared as a set", exported: inNamespaces("shared", "other"), accepted: inNamespaces("other", "shared"), namespace: "shared", resourceName: "s", wantLabel: true},
+		{name: "object in listed namespace", exported: inNamespaces("shared"), accepted: inNamespaces("shared"), namespace: "shared", resourceName: "s", wantLabel: true},
+		{name: "object in other namespace", exported: inNamespaces("shared"), accepted: inNamespaces("shared"), namespace: "other", resourceName: "s"},
+		{name: "cluster-scoped object", exported: inNamespaces("shared"), accepted: inNamespaces("shared"), resourceName: "s"},
+		{name: "object in selected namespace", exported: withSelector(map[string]string{"team": "a"}), accepted: withSelector(map[string]string{"team": "a"}), namespace: "shared", resourceName: "s", wantLabel: true},
+		{name: "object in unselected namespace", exported: withSelector(map[string]string{"team": "a"}), accepted: withSelector(map[string]string{"team": "a"}), namespace: "other", resourceName: "s"},
+		{name: "object in unknown namespace", exported: withSelector(map[string]string{"team": "a"}), accepted: withSelector(map[string]string{"team": "a"}), namespace: "unknown", resourceName: "s"},
+		{name: "listed namespace itself", exported: namespaceClaim, accepted: namespaceClaim, groupResource: corev1.Resource("namespaces"), resourceName: "shared", wantLabel: true},
+		{name: "unlisted namespace itself", exported: namespaceClaim, accepted: namespaceClaim, groupResource: corev1.Resource("namespaces"), resourceName: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding := &apisv1alpha1.APIBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "binding",
+					Annotations: map[string]string{logicalcluster.AnnotationKey: "root:consumer"},
+				},
+				Spec: apisv1alpha1.APIBindingSpec{
+					PermissionClaims: []apisv1alpha1.AcceptablePermissionClaim{
+						{PermissionClaim: tt.accepted, State: apisv1alpha1.ClaimAccepted},
+					},
+				},
+				Status: apisv1alpha1.APIBindingStatus{
+					BoundAPIExport: &apisv1alpha1.ExportReference{
+						Workspace: &apisv1alpha1.WorkspaceExportReference{Path: "root:provider", ExportName: "export"},
+					},
+					ExportPermissionClaims: []apisv1alpha1.PermissionClaim{tt.exported},
+				},
+			}
+
+			l := &Labeler{
+				listAPIBindingsAcceptingClaimedGroupResource: func(clusterName logicalcluster.Name, groupResource schema.GroupResource) ([]*apisv1alpha1.APIBinding, error) {
+					require.Equal(t, "root:consumer", clusterName.String())
+					return []*apisv1alpha1.APIBinding{binding}, nil
+				},
+				getAPIBinding: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIBinding, error) {
+					return nil, apierrors.NewNotFound(apisv1alpha1.Resource("apibindings"), name)
+				},
+				getNamespace: func(clusterName logicalcluster.Name, name string) (*corev1.Namespace, error) {
+					require.Equal(t, "root:consumer", clusterName.String())
+					if ns, found := namespaces[name]; found {
+						return ns, nil
+					}
+					return nil, apierrors.NewNotFound(corev1.Resource("namespaces"), name)
+				},
+			}
+
+			groupResource := tt.groupResource
+			if groupResource.Empty() {
+				groupResource = corev1.Resource("secrets")
+			}
+			got, err := l.LabelsFor(context.Background(), logicalcluster.New("root:consumer"), groupResource, tt.namespace, tt.resourceName)
+			require.NoError(t, err)
+
+			if !tt.wantLabel {
+				require.Empty(t, got)
+				return
+			}
+			k, v, err := permissionclaims.ToLabelKeyAndValue(logicalcluster.New("root:provider"), "export", tt.exported)
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{k: v}, got)
+		})
+	}
+}
@@ -18,6 +18,7 @@ package apibinding
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -156,6 +157,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	}
 
 	var needToWaitForRequeueWhenEstablished []string
+	var exportedSchemas []*apisv1alpha1.APIResourceSchema
 
 	for _, schemaName := range apiExport.Spec.LatestResourceSchemas {
 		schema, err := c.getAPIResourceSchema(apiExportClusterName, schemaName)
@@ -178,6 +180,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			return err
 		}
 		logger = logging.WithObject(logger, schema)
+		exportedSchemas = append(exportedSchemas, schema)
 
 		crd, err := generateCRD(schema)
 		if err != nil {
@@ -374,6 +377,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	conditions.MarkTrue(apiBinding, apisv1alpha1.APIExportValid)
 
 	apiBinding.Status.BoundAPIExport = &apiBinding.Spec.Reference
+	apiBinding.Status.ExportSchemaHash = resourceSchemasHash(exportedSchemas)
 
 	// Now that the Export is valid and is marked as such, we will add all the claims requested to the status.
 	apiBinding.Status.ExportPermissionClaims = apiExport.Spec.PermissionClaims
@@ -485,15 +489,43 @@ func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha
 		exportedSchemas = append(exportedSchemas, apiResourceSchema)
 	}
 
+	exportSchemaHash := resourceSchemasHash(exportedSchemas)
+	apiBinding.Status.ExportSchemaHash = exportSchemaHash
+
 	if apiExportLatestResourceSchemasChanged(apiBinding, exportedSchemas) {
+		if apiBinding.Spec.SchemaUpdatePolicy == apisv1alpha1.SchemaUpdatePolicyManual && apiBinding.Spec.AcceptedSchemaHash != exportSchemaHash {
+			logger.V(2).Info("APIExport's latestResourceSchemas has changed, waiting for the update to be accepted")
+			conditions.MarkFalse(
+				apiBinding,
+				apisv1alpha1.BindingUpToDate,
+				apisv1alpha1.SchemaUpdatePendingReason,
+				conditionsv1alpha1.ConditionSeverityInfo,
+				"APIExport %s|%s has updated resource schemas. Set spec.acceptedSchemaHash to %q to bind them",
+				apiExportClusterName,
+				apiBinding.Spec.Reference.Workspace.ExportName,
+				exportSchemaHash,
+			)
+			return false, nil
+		}
+
 		logger.V(2).Info("APIBinding needs rebinding because the APIExport's latestResourceSchemas has changed")
 		return true, nil
 	}
 
+	if conditions.GetReason(apiBinding, apisv1alpha1.BindingUpToDate) == apisv1alpha1.SchemaUpdatePendingReason {
+		// the API service provider reverted the pending update
+		conditions.MarkTrue(apiBinding, apisv1alpha1.BindingUpToDate)
+	}
+
 	return false, nil
 }
 
 func generateCRD(schema *apisv1alpha1.APIResourceSchema) (*apiextensionsv1.CustomResourceDefinition, error) {
+	// conversion webhooks are rejected by admission, but do not call out to them for schemas stored before
+	if conversion := schema.Spec.Conversion; conversion != nil && (conversion.Strategy != apiextensionsv1.NoneConverter || conversion.Webhook != nil) {
+		return nil, fmt.Errorf("conversion strategy %q of APIResourceSchema %s|%s is not supported", conversion.Strategy, logicalcluster.From(schema), schema.Name)
+	}
+
 	crd := &apiextensionsv1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: string(schema.UID),
@@ -505,9 +537,10 @@ func generateCRD(schema *apisv1alpha1.APIResourceSchema) (*apiextensionsv1.Custo
 			},
 		},
 		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
-			Group: schema.Spec.Group,
-			Names: schema.Spec.Names,
-			Scope: schema.Spec.Scope,
+			Group:      schema.Spec.Group,
+			Names:      schema.Spec.Names,
+			Scope:      schema.Spec.Scope,
+			Conversion: schema.Spec.Conversion.DeepCopy(),
 		},
 	}
 
@@ -561,6 +594,17 @@ func referencedAPIExportChanged(apiBinding *apisv1alpha1.APIBinding) bool {
 	return *apiBinding.Spec.Reference.Workspace != *apiBinding.Status.BoundAPIExport.Workspace
 }
 
+// resourceSchemasHash returns a hash identifying the given set of APIResourceSchemas, independent of their order.
+func resourceSchemasHash(schemas []*apisv1alpha1.APIResourceSchema) string {
+	uids := sets.NewString()
+	for _, schema := range schemas {
+		uids.Insert(string(schema.UID))
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(uids.List(), ",")))
+	return fmt.Sprintf("%x", hash)
+}
+
 func apiExportLatestResourceSchemasChanged(apiBinding *apisv1alpha1.APIBinding, exportedSchemas []*apisv1alpha1.APIResourceSchema) bool {
 	exportedSchemaUIDs := sets.NewString()
 	for _, exportedSchema := range exportedSchemas {
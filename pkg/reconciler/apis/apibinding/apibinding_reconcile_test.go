@@ -633,6 +633,19 @@ func TestReconcileBinding(t *testing.T) {
 }
 
 func TestReconcileBound(t *testing.T) {
+	updatedExport := &apisv1alpha1.APIExport{
+		Spec: apisv1alpha1.APIExportSpec{
+			LatestResourceSchemas: []string{"someresources", "moreresources"},
+		},
+	}
+	updatedSchemas := map[string]*apisv1alpha1.APIResourceSchema{
+		"someresources": {ObjectMeta: metav1.ObjectMeta{Name: "someresources", UID: "uid1"}},
+		"moreresources": {ObjectMeta: metav1.ObjectMeta{Name: "moreresources", UID: "uid3"}},
+	}
+	updatedSchemasHash := resourceSchemasHash([]*apisv1alpha1.APIResourceSchema{updatedSchemas["someresources"], updatedSchemas["moreresources"]})
+	pendingUpdate := bound.DeepCopy().WithSchemaUpdatePolicy(apisv1alpha1.SchemaUpdatePolicyManual, "")
+	conditions.MarkFalse(pendingUpdate, apisv1alpha1.BindingUpToDate, apisv1alpha1.SchemaUpdatePendingReason, conditionsv1alpha1.ConditionSeverityInfo, "")
+
 	tests := map[string]struct {
 		apiBinding            *apisv1alpha1.APIBinding
 		apiExport             *apisv1alpha1.APIExport
@@ -642,6 +655,8 @@ func TestReconcileBound(t *testing.T) {
 		wantPhase             string
 		wantError             bool
 		wantAPIExportNotFound bool
+		wantUpToDate          *bool
+		wantExportSchemaHash  string
 	}{
 		"rebinding when referenced export changes": {
 			apiBinding: bound.DeepCopy().
@@ -698,6 +713,36 @@ func TestReconcileBound(t *testing.T) {
 			wantRebinding: true,
 			wantPhase:     "Bound",
 		},
+		"no rebinding with manual schema update policy until the update is accepted": {
+			apiBinding:           bound.DeepCopy().WithSchemaUpdatePolicy(apisv1alpha1.SchemaUpdatePolicyManual, "").Build(),
+			apiExport:            updatedExport,
+			apiResourceSchemas:   updatedSchemas,
+			wantPhase:            "Bound",
+			wantUpToDate:         pointer.Bool(false),
+			wantExportSchemaHash: updatedSchemasHash,
+		},
+		"rebinding with manual schema update policy when the update is accepted": {
+			apiBinding:           bound.DeepCopy().WithSchemaUpdatePolicy(apisv1alpha1.SchemaUpdatePolicyManual, updatedSchemasHash).Build(),
+			apiExport:            updatedExport,
+			apiResourceSchemas:   updatedSchemas,
+			wantRebinding:        true,
+			wantPhase:            "Bound",
+			wantExportSchemaHash: updatedSchemasHash,
+		},
+		"pending schema update is cleared when the export reverts it": {
+			apiBinding: pendingUpdate.Build(),
+			apiExport: &apisv1alpha1.APIExport{
+				Spec: apisv1alpha1.APIExportSpec{
+					LatestResourceSchemas: []string{"someresources", "otherresources"},
+				},
+			},
+			apiResourceSchemas: map[string]*apisv1alpha1.APIResourceSchema{
+				"someresources":  {ObjectMeta: metav1.ObjectMeta{Name: "someresources", UID: "uid1"}},
+				"otherresources": {ObjectMeta: metav1.ObjectMeta{Name: "otherresources", UID: "uid2"}},
+			},
+			wantPhase:    "Bound",
+			wantUpToDate: pointer.Bool(true),
+		},
 		"APIExportValid warning condition set when error getting previously bound APIExport": {
 			apiBinding:            bound.Build(),
 			getAPIExportError:     apierrors.NewNotFound(schema.GroupResource{}, "foo"),
@@ -742,6 +787,24 @@ func TestReconcileBound(t *testing.T) {
 					Reason:   apisv1alpha1.APIExportNotFoundReason,
 				})
 			}
+
+			if tc.wantUpToDate != nil && *tc.wantUpToDate {
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:   apisv1alpha1.BindingUpToDate,
+					Status: corev1.ConditionTrue,
+				})
+			} else if tc.wantUpToDate != nil {
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:     apisv1alpha1.BindingUpToDate,
+					Status:   corev1.ConditionFalse,
+					Severity: conditionsv1alpha1.ConditionSeverityInfo,
+					Reason:   apisv1alpha1.SchemaUpdatePendingReason,
+					Message:  tc.wantExportSchemaHash,
+				})
+			}
+			if tc.wantExportSchemaHash != "" {
+				require.Equal(t, tc.wantExportSchemaHash, tc.apiBinding.Status.ExportSchemaHash)
+			}
 		})
 	}
 }
@@ -931,6 +994,33 @@ func TestCRDFromAPIResourceSchema(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"error when conversion webhook is set": {
+			schema: &apisv1alpha1.APIResourceSchema{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey: "my-cluster",
+					},
+					Name: "my-name",
+					UID:  types.UID("my-uuid"),
+				},
+				Spec: apisv1alpha1.APIResourceSchemaSpec{
+					Group: "my-group",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Singular: "widget", Kind: "Widget", ListKind: "WidgetList"},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apisv1alpha1.APIResourceVersion{
+						{Name: "v1", Served: true, Storage: true, Schema: runtime.RawExtension{Raw: []byte(`{"type":"object"}`)}},
+					},
+					Conversion: &apiextensionsv1.CustomResourceConversion{
+						Strategy: apiextensionsv1.WebhookConverter,
+						Webhook: &apiextensionsv1.WebhookConversion{
+							ClientConfig:             &apiextensionsv1.WebhookClientConfig{URL: pointer.String("https://provider.example.com/convert")},
+							ConversionReviewVersions: []string{"v1"},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		"error when schema is invalid": {
 			schema: &apisv1alpha1.APIResourceSchema{
 				Spec: apisv1alpha1.APIResourceSchemaSpec{
@@ -1009,6 +1099,12 @@ func (b *bindingBuilder) WithWorkspaceReference(path, exportName string) *bindin
 	return b
 }
 
+func (b *bindingBuilder) WithSchemaUpdatePolicy(policy apisv1alpha1.SchemaUpdatePolicy, acceptedSchemaHash string) *bindingBuilder {
+	b.Spec.SchemaUpdatePolicy = policy
+	b.Spec.AcceptedSchemaHash = acceptedSchemaHash
+	return b
+}
+
 func (b *bindingBuilder) WithPhase(phase apisv1alpha1.APIBindingPhaseType) *bindingBuilder {
 	b.Status.Phase = phase
 	return b
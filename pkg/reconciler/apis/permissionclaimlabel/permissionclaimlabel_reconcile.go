@@ -19,6 +19,7 @@ package permissionclaimlabel
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -64,49 +65,57 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 
 	logger = logging.WithObject(logger, apiExport)
 
-	exportedClaims := sets.NewString()
+	var unexpectedOrInvalidErrors []error
+
+	exportedClaims := claimSet{}
 	for _, claim := range apiExport.Spec.PermissionClaims {
-		exportedClaims.Insert(setKeyForClaim(claim))
+		if err := exportedClaims.insert(claim); err != nil {
+			unexpectedOrInvalidErrors = append(unexpectedOrInvalidErrors, err)
+		}
 	}
 
-	acceptedClaims := sets.NewString()
+	acceptedClaims := claimSet{}
 	for _, claim := range apiBinding.Spec.PermissionClaims {
 		if claim.State == apisv1alpha1.ClaimAccepted {
-			acceptedClaims.Insert(setKeyForClaim(claim.PermissionClaim))
+			if err := acceptedClaims.insert(claim.PermissionClaim); err != nil {
+				unexpectedOrInvalidErrors = append(unexpectedOrInvalidErrors, err)
+			}
 		}
 	}
 
-	appliedClaims := sets.NewString()
+	appliedClaims := claimSet{}
 	for _, claim := range apiBinding.Status.AppliedPermissionClaims {
-		appliedClaims.Insert(setKeyForClaim(claim))
+		if err := appliedClaims.insert(claim); err != nil {
+			unexpectedOrInvalidErrors = append(unexpectedOrInvalidErrors, err)
+		}
 	}
 
-	expectedClaims := exportedClaims.Intersection(acceptedClaims)
-	unexpectedClaims := acceptedClaims.Difference(expectedClaims)
-	needToApply := expectedClaims.Difference(appliedClaims)
-	needToRemove := appliedClaims.Difference(acceptedClaims)
-	allChanges := needToApply.Union(needToRemove)
+	expectedClaims := exportedClaims.intersection(acceptedClaims)
+	unexpectedClaims := acceptedClaims.difference(expectedClaims)
+	needToApply := expectedClaims.difference(appliedClaims)
+	needToRemove := appliedClaims.difference(expectedClaims)
+	allChanges := needToApply.union(needToRemove)
 
 	logger.V(6).Info("claim set details",
-		"expected", expectedClaims,
-		"unexpected", unexpectedClaims,
-		"toApply", needToApply,
-		"toRemove", needToRemove,
-		"all", allChanges,
+		"expected", expectedClaims.sortedKeys(),
+		"unexpected", unexpectedClaims.sortedKeys(),
+		"toApply", needToApply.sortedKeys(),
+		"toRemove", needToRemove.sortedKeys(),
+		"all", allChanges.sortedKeys(),
 	)
 
 	var allErrs []error
-	applyErrors := sets.NewString()
+	applyErrors := claimSet{}
 
-	for _, s := range allChanges.List() {
-		claim := claimFromSetKey(s)
-		claimLogger := logger.WithValues("claim", s)
+	for _, key := range allChanges.sortedKeys() {
+		claim := allChanges[key]
+		claimLogger := logger.WithValues("claim", key)
 
 		informer, gvr, err := c.getInformerForGroupResource(claim.Group, claim.Resource)
 		if err != nil {
 			allErrs = append(allErrs, fmt.Errorf("error getting informer for group=%q, resource=%q: %w", claim.Group, claim.Resource, err))
-			if acceptedClaims.Has(s) {
-				applyErrors.Insert(s)
+			if acceptedClaims.has(key) {
+				applyErrors[key] = claim
 			}
 			continue
 		}
@@ -115,8 +124,8 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 		objs, err := informer.Informer().GetIndexer().ByIndex(indexers.ByLogicalCluster, clusterName.String())
 		if err != nil {
 			allErrs = append(allErrs, fmt.Errorf("error listing group=%q, resource=%q: %w", claim.Group, claim.Resource, err))
-			if acceptedClaims.Has(s) {
-				applyErrors.Insert(s)
+			if acceptedClaims.has(key) {
+				applyErrors[key] = claim
 			}
 			continue
 		}
@@ -146,15 +155,14 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 		if len(claimErrs) > 0 {
 			allErrs = append(allErrs, claimErrs...)
 
-			if acceptedClaims.Has(s) {
-				applyErrors.Insert(s)
+			if acceptedClaims.has(key) {
+				applyErrors[key] = claim
 			}
 		}
 	}
 
-	var unexpectedOrInvalidErrors []error
-	for _, s := range unexpectedClaims.List() {
-		claim := claimFromSetKey(s)
+	for _, key := range unexpectedClaims.sortedKeys() {
+		claim := unexpectedClaims[key]
 		unexpectedOrInvalidErrors = append(unexpectedOrInvalidErrors, fmt.Errorf("unexpected/invalid claim for %s.%s (identity %q)", claim.Resource, claim.Group, claim.IdentityHash))
 	}
 	if len(unexpectedOrInvalidErrors) > 0 {
@@ -178,11 +186,10 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 		conditions.MarkTrue(apiBinding, apisv1alpha1.PermissionClaimsValid)
 	}
 
-	fullyApplied := expectedClaims.Difference(applyErrors)
+	fullyApplied := expectedClaims.difference(applyErrors)
 	apiBinding.Status.AppliedPermissionClaims = []apisv1alpha1.PermissionClaim{}
-	for _, s := range fullyApplied.UnsortedList() {
-		claim := claimFromSetKey(s)
-		apiBinding.Status.AppliedPermissionClaims = append(apiBinding.Status.AppliedPermissionClaims, claim)
+	for _, key := range fullyApplied.sortedKeys() {
+		apiBinding.Status.AppliedPermissionClaims = append(apiBinding.Status.AppliedPermissionClaims, fullyApplied[key])
 	}
 
 	if len(allErrs) > 0 {
@@ -215,19 +222,112 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 	return nil
 }
 
-func setKeyForClaim(claim apisv1alpha1.PermissionClaim) string {
-	return fmt.Sprintf("%s/%s/%s", claim.Resource, claim.Group, claim.IdentityHash)
+// claimKey identifies a permission claim including its namespace and verb restrictions, such that claims
+// differing only in their restrictions are different claims that must be accepted separately.
+type claimKey struct {
+	resource     string
+	group        string
+	identityHash string
+
+	// namespaces and verbs are sorted and comma separated. Neither namespace names nor verbs contain commas.
+	namespaces string
+	verbs      string
+
+	hasNamespaceSelector bool
+	// namespaceSelector is the canonical string representation of the namespace selector.
+	namespaceSelector string
+}
+
+func newClaimKey(claim apisv1alpha1.PermissionClaim) (claimKey, error) {
+	key := claimKey{
+		resource:     claim.Resource,
+		group:        claim.Group,
+		identityHash: claim.IdentityHash,
+		namespaces:   strings.Join(sets.NewString(claim.Namespaces...).List(), ","),
+		verbs:        strings.Join(sets.NewString(claim.Verbs...).List(), ","),
+	}
+	if claim.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(claim.NamespaceSelector)
+		if err != nil {
+			return claimKey{}, fmt.Errorf("invalid namespace selector of claim for %s: %w", claim, err)
+		}
+		key.hasNamespaceSelector = true
+		key.namespaceSelector = selector.String()
+	}
+	return key, nil
+}
+
+func (k claimKey) String() string {
+	s := fmt.Sprintf("%s/%s/%s", k.resource, k.group, k.identityHash)
+	if k.namespaces != "" {
+		s += fmt.Sprintf(" namespaces=%s", k.namespaces)
+	}
+	if k.hasNamespaceSelector {
+		s += fmt.Sprintf(" namespaceSelector=%q", k.namespaceSelector)
+	}
+	if k.verbs != "" {
+		s += fmt.Sprintf(" verbs=%s", k.verbs)
+	}
+	return s
+}
+
+// claimSet is a set of permission claims by their claimKey.
+type claimSet map[claimKey]apisv1alpha1.PermissionClaim
+
+func (s claimSet) insert(claim apisv1alpha1.PermissionClaim) error {
+	key, err := newClaimKey(claim)
+	if err != nil {
+		return err
+	}
+	s[key] = claim
+	return nil
+}
+
+func (s claimSet) has(key claimKey) bool {
+	_, ok := s[key]
+	return ok
+}
+
+func (s claimSet) intersection(other claimSet) claimSet {
+	ret := claimSet{}
+	for key, claim := range s {
+		if other.has(key) {
+			ret[key] = claim
+		}
+	}
+	return ret
+}
+
+func (s claimSet) difference(other claimSet) claimSet {
+	ret := claimSet{}
+	for key, claim := range s {
+		if !other.has(key) {
+			ret[key] = claim
+		}
+	}
+	return ret
+}
+
+func (s claimSet) union(other claimSet) claimSet {
+	ret := claimSet{}
+	for key, claim := range s {
+		ret[key] = claim
+	}
+	for key, claim := range other {
+		ret[key] = claim
+	}
+	return ret
 }
 
-func claimFromSetKey(key string) apisv1alpha1.PermissionClaim {
-	parts := strings.SplitN(key, "/", 3)
-	return apisv1alpha1.PermissionClaim{
-		GroupResource: apisv1alpha1.GroupResource{
-			Group:    parts[1],
-			Resource: parts[0],
-		},
-		IdentityHash: parts[2],
+func (s claimSet) sortedKeys() []claimKey {
+	keys := make([]claimKey, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
 	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
 }
 
 func (c *controller) getInformerForGroupResource(group, resource string) (kubernetesinformers.GenericInformer, schema.GroupVersionResource, error) {
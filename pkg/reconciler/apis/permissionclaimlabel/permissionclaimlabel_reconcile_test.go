@@ -22,53 +22,107 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 )
 
-func TestClaimSetKeys(t *testing.T) {
+func TestClaimKeys(t *testing.T) {
+	secrets := apisv1alpha1.GroupResource{Resource: "secrets"}
+	selector := func(matchLabels map[string]string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchLabels: matchLabels}
+	}
+
 	tests := map[string]struct {
-		claim apisv1alpha1.PermissionClaim
-		key   string
+		a, b  apisv1alpha1.PermissionClaim
+		equal bool
 	}{
-		"core gr": {
-			claim: apisv1alpha1.PermissionClaim{
-				GroupResource: apisv1alpha1.GroupResource{
-					Group:    "",
-					Resource: "configmaps",
-				},
-				IdentityHash: "",
-			},
-			key: "configmaps//",
+		"same claim": {
+			a:     apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Group: "apis.kcp.dev", Resource: "apibindings"}, IdentityHash: "hash"},
+			b:     apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Group: "apis.kcp.dev", Resource: "apibindings"}, IdentityHash: "hash"},
+			equal: true,
+		},
+		"different identity hash": {
+			a: apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Group: "apis.kcp.dev", Resource: "apibindings"}, IdentityHash: "hash"},
+			b: apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Group: "apis.kcp.dev", Resource: "apibindings"}, IdentityHash: "other"},
+		},
+		"namespaces and verbs in different order": {
+			a:     apisv1alpha1.PermissionClaim{GroupResource: secrets, Namespaces: []string{"a", "b"}, Verbs: []string{"get", "list"}},
+			b:     apisv1alpha1.PermissionClaim{GroupResource: secrets, Namespaces: []string{"b", "a"}, Verbs: []string{"list", "get"}},
+			equal: true,
 		},
-		"non-core built-in gr": {
-			claim: apisv1alpha1.PermissionClaim{
-				GroupResource: apisv1alpha1.GroupResource{
-					Group:    "rbac.authorization.k8s.io",
-					Resource: "roles",
-				},
-				IdentityHash: "",
-			},
-			key: "roles/rbac.authorization.k8s.io/",
+		"different namespaces": {
+			a: apisv1alpha1.PermissionClaim{GroupResource: secrets, Namespaces: []string{"a"}},
+			b: apisv1alpha1.PermissionClaim{GroupResource: secrets, Namespaces: []string{"a", "b"}},
 		},
-		"3rd party gr + hash": {
-			claim: apisv1alpha1.PermissionClaim{
-				GroupResource: apisv1alpha1.GroupResource{
-					Group:    "apis.kcp.dev",
-					Resource: "apibindings",
-				},
-				IdentityHash: "hash",
-			},
-			key: "apibindings/apis.kcp.dev/hash",
+		"namespace restricted and unrestricted": {
+			a: apisv1alpha1.PermissionClaim{GroupResource: secrets, Namespaces: []string{"a"}},
+			b: apisv1alpha1.PermissionClaim{GroupResource: secrets},
+		},
+		"verb restricted and unrestricted": {
+			a: apisv1alpha1.PermissionClaim{GroupResource: secrets, Verbs: []string{"get"}},
+			b: apisv1alpha1.PermissionClaim{GroupResource: secrets},
+		},
+		"same selector": {
+			a:     apisv1alpha1.PermissionClaim{GroupResource: secrets, NamespaceSelector: selector(map[string]string{"team": "x", "env": "prod"})},
+			b:     apisv1alpha1.PermissionClaim{GroupResource: secrets, NamespaceSelector: selector(map[string]string{"env": "prod", "team": "x"})},
+			equal: true,
+		},
+		"different selectors": {
+			a: apisv1alpha1.PermissionClaim{GroupResource: secrets, NamespaceSelector: selector(map[string]string{"team": "x"})},
+			b: apisv1alpha1.PermissionClaim{GroupResource: secrets, NamespaceSelector: selector(map[string]string{"team": "y"})},
+		},
+		"empty selector and no selector": {
+			a: apisv1alpha1.PermissionClaim{GroupResource: secrets, NamespaceSelector: selector(nil)},
+			b: apisv1alpha1.PermissionClaim{GroupResource: secrets},
 		},
 	}
 
 	for testName, tc := range tests {
 		t.Run(testName, func(t *testing.T) {
-			encoded := setKeyForClaim(tc.claim)
-			require.Equal(t, tc.key, encoded)
-
-			decoded := claimFromSetKey(tc.key)
-			require.Empty(t, cmp.Diff(tc.claim, decoded))
+			a, err := newClaimKey(tc.a)
+			require.NoError(t, err)
+			b, err := newClaimKey(tc.b)
+			require.NoError(t, err)
+			require.Equal(t, tc.equal, a == b, "keys %s and %s", a, b)
 		})
 	}
 }
+
+func TestClaimKeyInvalidSelector(t *testing.T) {
+	_, err := newClaimKey(apisv1alpha1.PermissionClaim{
+		GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"},
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Like"}},
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestClaimSet(t *testing.T) {
+	configmaps := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"}}
+	secrets := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}}
+	readOnlySecrets := apisv1alpha1.PermissionClaim{GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"}, Verbs: []string{"get"}}
+
+	newSet := func(claims ...apisv1alpha1.PermissionClaim) claimSet {
+		s := claimSet{}
+		for _, claim := range claims {
+			require.NoError(t, s.insert(claim))
+		}
+		return s
+	}
+	claims := func(s claimSet) []apisv1alpha1.PermissionClaim {
+		var ret []apisv1alpha1.PermissionClaim
+		for _, key := range s.sortedKeys() {
+			ret = append(ret, s[key])
+		}
+		return ret
+	}
+
+	exported := newSet(configmaps, readOnlySecrets)
+	accepted := newSet(configmaps, secrets)
+
+	require.Empty(t, cmp.Diff([]apisv1alpha1.PermissionClaim{configmaps}, claims(exported.intersection(accepted))))
+	require.Empty(t, cmp.Diff([]apisv1alpha1.PermissionClaim{secrets}, claims(accepted.difference(exported))))
+	require.Empty(t, cmp.Diff([]apisv1alpha1.PermissionClaim{configmaps, secrets, readOnlySecrets}, claims(exported.union(accepted))))
+}
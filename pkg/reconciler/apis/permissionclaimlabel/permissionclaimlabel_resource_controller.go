@@ -25,16 +25,22 @@ import (
 
 	"github.com/go-logr/logr"
 	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/indexers"
@@ -53,6 +59,7 @@ func NewResourceController(
 	dynamicClusterClient dynamic.Interface,
 	dynamicDiscoverySharedInformerFactory *informer.DynamicDiscoverySharedInformerFactory,
 	apiBindingInformer apisinformers.APIBindingInformer,
+	namespaceInformer coreinformers.NamespaceInformer,
 ) (*resourceController, error) {
 	if err := apiBindingInformer.Informer().GetIndexer().AddIndexers(
 		cache.Indexers{
@@ -67,7 +74,11 @@ func NewResourceController(
 		kcpClusterClient:       kcpClusterClient,
 		dynamicClusterClient:   dynamicClusterClient,
 		ddsif:                  dynamicDiscoverySharedInformerFactory,
-		permissionClaimLabeler: permissionclaim.NewLabeler(apiBindingInformer),
+		permissionClaimLabeler: permissionclaim.NewLabeler(apiBindingInformer, namespaceInformer),
+		listAPIBindingsAcceptingClaimedGroupResource: func(clusterName logicalcluster.Name, groupResource schema.GroupResource) ([]*apisv1alpha1.APIBinding, error) {
+			indexKey := indexers.ClusterAndGroupResourceValue(clusterName, groupResource)
+			return indexers.ByIndex[*apisv1alpha1.APIBinding](apiBindingInformer.Informer().GetIndexer(), indexers.APIBindingByClusterAndAcceptedClaimedGroupResources, indexKey)
+		},
 	}
 
 	logger := logging.WithReconciler(klog.Background(), controllerName)
@@ -78,6 +89,23 @@ func NewResourceController(
 		DeleteFunc: nil, // Nothing to do.
 	})
 
+	namespaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueForNamespace(logger, obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldNamespace, ok := oldObj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			namespace, ok := obj.(*corev1.Namespace)
+			if !ok {
+				return
+			}
+			if !equality.Semantic.DeepEqual(oldNamespace.Labels, namespace.Labels) {
+				c.enqueueForNamespace(logger, obj)
+			}
+		},
+	})
+
 	return c, nil
 
 }
@@ -90,6 +118,8 @@ type resourceController struct {
 	dynamicClusterClient   dynamic.Interface
 	ddsif                  *informer.DynamicDiscoverySharedInformerFactory
 	permissionClaimLabeler *permissionclaim.Labeler
+
+	listAPIBindingsAcceptingClaimedGroupResource func(clusterName logicalcluster.Name, groupResource schema.GroupResource) ([]*apisv1alpha1.APIBinding, error)
 }
 
 // enqueueForResource adds the resource (gvr + obj) to the queue.
@@ -107,6 +137,43 @@ func (c *resourceController) enqueueForResource(logger logr.Logger, gvr schema.G
 	c.queue.Add(queueKey)
 }
 
+// enqueueForNamespace adds all claimed resources in the namespace to the queue, because permission
+// claims with a namespace selector might apply differently to them after the namespace changed.
+func (c *resourceController) enqueueForNamespace(logger logr.Logger, obj interface{}) {
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		utilruntime.HandleError(fmt.Errorf("obj is supposed to be a Namespace, but is %T", obj))
+		return
+	}
+	clusterName := logicalcluster.From(namespace)
+
+	listers, _ := c.ddsif.Listers()
+	for gvr := range listers {
+		bindings, err := c.listAPIBindingsAcceptingClaimedGroupResource(clusterName, gvr.GroupResource())
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		if len(bindings) == 0 {
+			continue
+		}
+
+		inf, err := c.ddsif.ForResource(gvr)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		objs, err := inf.Informer().GetIndexer().ByIndex(indexers.ByLogicalClusterAndNamespace, clusters.ToClusterAwareKey(clusterName, namespace.Name))
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		for _, obj := range objs {
+			c.enqueueForResource(logger, gvr, obj)
+		}
+	}
+}
+
 // Start starts the controller, which stops when ctx.Done() is closed.
 func (c *resourceController) Start(ctx context.Context, numThreads int) {
 	defer utilruntime.HandleCrash()
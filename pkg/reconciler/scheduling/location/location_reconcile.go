@@ -86,7 +86,7 @@ func (r *statusReconciler) reconcile(ctx context.Context, location *schedulingv1
 	if err != nil {
 		return reconcileStatusStop, err
 	}
-	available := len(FilterReady(locationClusters))
+	available := len(FilterSchedulable(FilterReady(locationClusters)))
 	location.Status.Instances = uint32Ptr(uint32(len(locationClusters)))
 	location.Status.AvailableInstances = uint32Ptr(uint32(available))
 
@@ -50,13 +50,24 @@ func LocationSyncTargets(syncTargets []*workloadv1alpha1.SyncTarget, location *s
 func FilterReady(syncTargets []*workloadv1alpha1.SyncTarget) []*workloadv1alpha1.SyncTarget {
 	ready := make([]*workloadv1alpha1.SyncTarget, 0, len(syncTargets))
 	for _, wc := range syncTargets {
-		if conditions.IsTrue(wc, conditionsv1alpha1.ReadyCondition) && !wc.Spec.Unschedulable {
+		if conditions.IsTrue(wc, conditionsv1alpha1.ReadyCondition) {
 			ready = append(ready, wc)
 		}
 	}
 	return ready
 }
 
+// FilterSchedulable filters out the sync targets that are cordoned and must not get new workloads.
+func FilterSchedulable(syncTargets []*workloadv1alpha1.SyncTarget) []*workloadv1alpha1.SyncTarget {
+	ret := make([]*workloadv1alpha1.SyncTarget, 0, len(syncTargets))
+	for _, wc := range syncTargets {
+		if !wc.Spec.Unschedulable {
+			ret = append(ret, wc)
+		}
+	}
+	return ret
+}
+
 // FilterNonEvicting filters out the evicting sync targets.
 func FilterNonEvicting(syncTargets []*workloadv1alpha1.SyncTarget) []*workloadv1alpha1.SyncTarget {
 	ret := make([]*workloadv1alpha1.SyncTarget, 0, len(syncTargets))
@@ -135,7 +135,9 @@ func NewController(
 			UpdateFunc: func(old, obj interface{}) {
 				oldLoc := old.(*schedulingv1alpha1.Location)
 				newLoc := obj.(*schedulingv1alpha1.Location)
-				if !reflect.DeepEqual(oldLoc.Spec, newLoc.Spec) || !reflect.DeepEqual(oldLoc.Labels, newLoc.Labels) {
+				// available instances change when sync targets are cordoned or drained.
+				if !reflect.DeepEqual(oldLoc.Spec, newLoc.Spec) || !reflect.DeepEqual(oldLoc.Labels, newLoc.Labels) ||
+					!reflect.DeepEqual(oldLoc.Status.AvailableInstances, newLoc.Status.AvailableInstances) {
 					c.enqueueLocation(obj)
 				}
 			},
@@ -46,7 +46,7 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 		locationWorkspace = logicalcluster.From(placement)
 	}
 
-	validLocationNames, err := r.validLocationNames(placement, locationWorkspace)
+	validLocationNames, availableLocationNames, err := r.validLocationNames(placement, locationWorkspace)
 	if err != nil {
 		conditions.MarkFalse(placement, schedulingv1alpha1.PlacementReady, schedulingv1alpha1.LocationNotFoundReason, conditionsv1alpha1.ConditionSeverityError, err.Error())
 		return reconcileStatusContinue, placement, err
@@ -67,10 +67,15 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 			return reconcileStatusContinue, placement, nil
 		}
 
-		conditions.MarkTrue(placement, schedulingv1alpha1.PlacementReady)
-		return reconcileStatusContinue, placement, nil
+		// keep the selected location unless all of its instances are cordoned or not ready, and
+		// the workloads can move to another location.
+		if !isDrainedLocationSelected(placement, validLocationNames, availableLocationNames) {
+			conditions.MarkTrue(placement, schedulingv1alpha1.PlacementReady)
+			return reconcileStatusContinue, placement, nil
+		}
 	case schedulingv1alpha1.PlacementUnbound:
-		if isValidLocationSelected(placement, locationWorkspace, validLocationNames) {
+		if isValidLocationSelected(placement, locationWorkspace, validLocationNames) &&
+			!isDrainedLocationSelected(placement, validLocationNames, availableLocationNames) {
 			// if the selected location is valid, keep it.
 			conditions.MarkTrue(placement, schedulingv1alpha1.PlacementReady)
 			return reconcileStatusContinue, placement, nil
@@ -90,8 +95,13 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 		return reconcileStatusContinue, placement, nil
 	}
 
-	candidates := make([]string, 0, validLocationNames.Len())
-	for loc := range validLocationNames {
+	// prefer locations with available instances.
+	candidateNames := validLocationNames
+	if availableLocationNames.Len() > 0 {
+		candidateNames = availableLocationNames
+	}
+	candidates := make([]string, 0, candidateNames.Len())
+	for loc := range candidateNames {
 		candidates = append(candidates, loc)
 	}
 
@@ -108,12 +118,15 @@ func (r *placementReconciler) reconcile(ctx context.Context, placement *scheduli
 	return reconcileStatusContinue, placement, nil
 }
 
-func (r *placementReconciler) validLocationNames(placement *schedulingv1alpha1.Placement, locationWorkspace logicalcluster.Name) (sets.String, error) {
+// validLocationNames returns the names of the locations selected by the placement, and the subset of those
+// that have available instances or whose instances have not been counted yet.
+func (r *placementReconciler) validLocationNames(placement *schedulingv1alpha1.Placement, locationWorkspace logicalcluster.Name) (sets.String, sets.String, error) {
 	selectedLocations := sets.NewString()
+	availableLocations := sets.NewString()
 
 	locations, err := r.listLocations(locationWorkspace)
 	if err != nil {
-		return selectedLocations, err
+		return selectedLocations, availableLocations, err
 	}
 
 	for _, loc := range locations {
@@ -130,11 +143,14 @@ func (r *placementReconciler) validLocationNames(placement *schedulingv1alpha1.P
 
 			if selector.Matches(labels.Set(loc.Labels)) {
 				selectedLocations.Insert(loc.Name)
+				if loc.Status.AvailableInstances == nil || *loc.Status.AvailableInstances > 0 {
+					availableLocations.Insert(loc.Name)
+				}
 			}
 		}
 	}
 
-	return selectedLocations, nil
+	return selectedLocations, availableLocations, nil
 }
 
 func isValidLocationSelected(placement *schedulingv1alpha1.Placement, cluster logicalcluster.Name, validLocationNames sets.String) bool {
@@ -152,3 +168,13 @@ func isValidLocationSelected(placement *schedulingv1alpha1.Placement, cluster lo
 
 	return true
 }
+
+// isDrainedLocationSelected returns true if the selected location has no available instances, e.g. because
+// all of them are cordoned or drained, while other valid locations have.
+func isDrainedLocationSelected(placement *schedulingv1alpha1.Placement, validLocationNames, availableLocationNames sets.String) bool {
+	if placement.Status.SelectedLocation == nil || !validLocationNames.Has(placement.Status.SelectedLocation.LocationName) {
+		return false
+	}
+
+	return !availableLocationNames.Has(placement.Status.SelectedLocation.LocationName) && availableLocationNames.Len() > 0
+}
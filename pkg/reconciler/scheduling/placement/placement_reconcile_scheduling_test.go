@@ -160,6 +160,73 @@ func TestPlacementScheduling(t *testing.T) {
 			wantPhase:  schedulingv1alpha1.PlacementPending,
 			wantStatus: corev1.ConditionFalse,
 		},
+		{
+			name:  "move bound placement away from drained location",
+			phase: schedulingv1alpha1.PlacementBound,
+			locationSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{
+						"cloud": "aws",
+					},
+				},
+			},
+			selectedLocation: &schedulingv1alpha1.LocationReference{
+				LocationName: "aws",
+			},
+			locations: []*schedulingv1alpha1.Location{
+				withAvailableInstances(newLocation("aws", map[string]string{"cloud": "aws"}), 0),
+				withAvailableInstances(newLocation("aws-1", map[string]string{"cloud": "aws"}), 1),
+			},
+			wantPhase:  schedulingv1alpha1.PlacementUnbound,
+			wantStatus: corev1.ConditionTrue,
+			wantSelectLocation: &schedulingv1alpha1.LocationReference{
+				LocationName: "aws-1",
+			},
+		},
+		{
+			name:  "stick to drained location without alternative",
+			phase: schedulingv1alpha1.PlacementBound,
+			locationSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{
+						"cloud": "aws",
+					},
+				},
+			},
+			selectedLocation: &schedulingv1alpha1.LocationReference{
+				LocationName: "aws",
+			},
+			locations: []*schedulingv1alpha1.Location{
+				withAvailableInstances(newLocation("aws", map[string]string{"cloud": "aws"}), 0),
+				withAvailableInstances(newLocation("aws-1", map[string]string{"cloud": "aws"}), 0),
+				withAvailableInstances(newLocation("gcp", map[string]string{"cloud": "gcp"}), 1),
+			},
+			wantPhase:  schedulingv1alpha1.PlacementBound,
+			wantStatus: corev1.ConditionTrue,
+			wantSelectLocation: &schedulingv1alpha1.LocationReference{
+				LocationName: "aws",
+			},
+		},
+		{
+			name:  "prefer location with available instances",
+			phase: schedulingv1alpha1.PlacementPending,
+			locationSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{
+						"cloud": "aws",
+					},
+				},
+			},
+			locations: []*schedulingv1alpha1.Location{
+				withAvailableInstances(newLocation("aws", map[string]string{"cloud": "aws"}), 0),
+				withAvailableInstances(newLocation("aws-1", map[string]string{"cloud": "aws"}), 2),
+			},
+			wantPhase:  schedulingv1alpha1.PlacementUnbound,
+			wantStatus: corev1.ConditionTrue,
+			wantSelectLocation: &schedulingv1alpha1.LocationReference{
+				LocationName: "aws-1",
+			},
+		},
 		{
 			name:  "get location error",
 			phase: schedulingv1alpha1.PlacementUnbound,
@@ -232,3 +299,8 @@ func newLocation(name string, labels map[string]string) *schedulingv1alpha1.Loca
 		},
 	}
 }
+
+func withAvailableInstances(location *schedulingv1alpha1.Location, available uint32) *schedulingv1alpha1.Location {
+	location.Status.AvailableInstances = &available
+	return location
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapresources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	kcpclienthelper "github.com/kcp-dev/apimachinery/pkg/client"
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	confighelpers "github.com/kcp-dev/kcp/config/helpers"
+	admission "github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetypeexists"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/committer"
+)
+
+const (
+	ControllerName = "kcp-bootstrapresources-initializer"
+)
+
+// NewController returns a new controller which creates the objects referenced by the bootstrapResources
+// of ClusterWorkspaceTypes in new ClusterWorkspaces.
+//
+// The config, the clients and the ClusterWorkspace informer are expected to go through the initializing
+// workspaces virtual workspace for the system:bootstrapresources initializer, such that the objects are
+// created as the user who created the workspace.
+func NewController(
+	config *rest.Config,
+	dynamicClusterClient dynamic.Interface,
+	kcpClusterClient kcpclient.Interface,
+	clusterWorkspaceInformer tenancyinformer.ClusterWorkspaceInformer,
+	clusterWorkspaceTypeInformer tenancyinformer.ClusterWorkspaceTypeInformer,
+	configMapInformer coreinformers.ConfigMapInformer,
+	batteriesIncluded sets.String,
+) (*controller, error) {
+	c := &controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), ControllerName),
+
+		listClusterWorkspaces: func() ([]*tenancyv1alpha1.ClusterWorkspace, error) {
+			return clusterWorkspaceInformer.Lister().List(labels.Everything())
+		},
+		getClusterWorkspace: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+			return clusterWorkspaceInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
+		getClusterWorkspaceType: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+			return clusterWorkspaceTypeInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
+		getConfigMap: func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+			return configMapInformer.Lister().ConfigMaps(namespace).Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
+
+		createResources: func(ctx context.Context, clusterName logicalcluster.Name, manifests []manifest) error {
+			discoveryClient, err := discovery.NewDiscoveryClientForConfig(kcpclienthelper.SetCluster(rest.CopyConfig(config), clusterName))
+			if err != nil {
+				return err
+			}
+			mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+			ctx = logicalcluster.WithCluster(ctx, clusterName)
+			var errs []error
+			for _, m := range manifests {
+				if err := confighelpers.CreateResourcesFromYAML(ctx, dynamicClusterClient, mapper, batteriesIncluded, m.name, m.raw); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return utilerrors.NewAggregate(errs)
+		},
+
+		commit: committer.NewCommitter[*tenancyv1alpha1.ClusterWorkspace, *tenancyv1alpha1.ClusterWorkspaceSpec, *tenancyv1alpha1.ClusterWorkspaceStatus](kcpClusterClient.TenancyV1alpha1().ClusterWorkspaces()),
+	}
+
+	c.transitiveTypeResolver = admission.NewTransitiveTypeResolver(c.getClusterWorkspaceType)
+
+	logger := logging.WithReconciler(klog.Background(), ControllerName)
+
+	clusterWorkspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueClusterWorkspace(obj, logger)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueueClusterWorkspace(obj, logger)
+		},
+	})
+
+	clusterWorkspaceTypeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueClusterWorkspaceType(obj, logger)
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			c.enqueueClusterWorkspaceType(obj, logger)
+		},
+	})
+
+	return c, nil
+}
+
+type clusterWorkspaceResource = committer.Resource[*tenancyv1alpha1.ClusterWorkspaceSpec, *tenancyv1alpha1.ClusterWorkspaceStatus]
+
+// manifest is one entry of a bootstrap resources ConfigMap.
+type manifest struct {
+	// name identifies the manifest in errors.
+	name string
+	raw  []byte
+}
+
+// controller creates the objects referenced by the bootstrapResources of ClusterWorkspaceTypes
+// in initializing ClusterWorkspaces.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	listClusterWorkspaces   func() ([]*tenancyv1alpha1.ClusterWorkspace, error)
+	getClusterWorkspace     func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error)
+	getClusterWorkspaceType func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error)
+	getConfigMap            func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error)
+
+	createResources func(ctx context.Context, clusterName logicalcluster.Name, manifests []manifest) error
+
+	transitiveTypeResolver transitiveTypeResolver
+
+	// commit creates a patch and submits it, if needed.
+	commit func(ctx context.Context, new, old *clusterWorkspaceResource) error
+}
+
+type transitiveTypeResolver interface {
+	Resolve(t *tenancyv1alpha1.ClusterWorkspaceType) ([]*tenancyv1alpha1.ClusterWorkspaceType, error)
+}
+
+func (c *controller) enqueueClusterWorkspace(obj interface{}, logger logr.Logger) {
+	key, err := kcpcache.DeletionHandlingMetaClusterNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logging.WithQueueKey(logger, key).V(2).Info("queueing ClusterWorkspace")
+	c.queue.Add(key)
+}
+
+// enqueueClusterWorkspaceType enqueues all clusterworkspaces (which are only those that are initializing, because of
+// how the informer is supposed to be configured) whenever a clusterworkspacetype with bootstrap resources changes.
+func (c *controller) enqueueClusterWorkspaceType(obj interface{}, logger logr.Logger) {
+	cwt, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceType)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a ClusterWorkspaceType, but is %T", obj))
+		return
+	}
+
+	if cwt.Spec.BootstrapResources == nil {
+		return
+	}
+
+	list, err := c.listClusterWorkspaces()
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error listing clusterworkspaces: %w", err))
+		return
+	}
+
+	for _, ws := range list {
+		logger := logging.WithObject(logger, ws)
+		c.enqueueClusterWorkspace(ws, logger)
+	}
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+	logger := logging.WithReconciler(klog.FromContext(ctx), ControllerName)
+	ctx = klog.NewContext(ctx, logger)
+
+	logger.Info("Starting controller")
+	defer logger.Info("Shutting down controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+	<-ctx.Done()
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(klog.FromContext(ctx), key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(1).Info("processing key")
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%s: failed to sync %q, err: %w", ControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	logger := klog.FromContext(ctx)
+
+	parent, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+	if err != nil {
+		logger.Error(err, "unable to decode key")
+		return nil
+	}
+
+	clusterWorkspace, err := c.getClusterWorkspace(parent, name)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to get ClusterWorkspace from lister", "parentCluster", parent, "clusterWorkspace", name)
+		}
+
+		return nil // nothing we can do here
+	}
+
+	old := clusterWorkspace
+	clusterWorkspace = clusterWorkspace.DeepCopy()
+
+	logger = logging.WithObject(logger, clusterWorkspace)
+	ctx = klog.NewContext(ctx, logger)
+
+	var errs []error
+	if err := c.reconcile(ctx, clusterWorkspace); err != nil {
+		errs = append(errs, err)
+	}
+
+	// If the object being reconciled changed as a result, update it.
+	oldResource := &clusterWorkspaceResource{ObjectMeta: old.ObjectMeta, Spec: &old.Spec, Status: &old.Status}
+	newResource := &clusterWorkspaceResource{ObjectMeta: clusterWorkspace.ObjectMeta, Spec: &clusterWorkspace.Spec, Status: &clusterWorkspace.Status}
+	if err := c.commit(ctx, oldResource, newResource); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
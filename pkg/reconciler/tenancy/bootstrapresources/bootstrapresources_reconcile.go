@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapresources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/initialization"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterWorkspace *tenancyv1alpha1.ClusterWorkspace) error {
+	if clusterWorkspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseInitializing ||
+		!initialization.InitializerPresent(tenancyv1alpha1.ClusterWorkspaceBootstrapResourcesInitializer, clusterWorkspace.Status.Initializers) {
+		return nil
+	}
+
+	logger := klog.FromContext(ctx).WithValues(
+		"clusterWorkspaceType.path", clusterWorkspace.Spec.Type.Path,
+		"clusterWorkspaceType.name", clusterWorkspace.Spec.Type.Name,
+	)
+
+	clusterName := logicalcluster.From(clusterWorkspace).Join(clusterWorkspace.Name)
+	logger.V(2).Info("creating bootstrap resources for workspace")
+
+	// Start with the ClusterWorkspaceType specified by the ClusterWorkspace
+	leafCWT, err := c.getClusterWorkspaceType(logicalcluster.New(clusterWorkspace.Spec.Type.Path), tenancyv1alpha1.ObjectName(clusterWorkspace.Spec.Type.Name))
+	if err != nil {
+		logger.Error(err, "error getting ClusterWorkspaceType")
+
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized,
+			tenancyv1alpha1.WorkspaceInitializedClusterWorkspaceTypeInvalid,
+			conditionsv1alpha1.ConditionSeverityError,
+			"error getting ClusterWorkspaceType %s|%s: %v",
+			clusterWorkspace.Spec.Type.Path, clusterWorkspace.Spec.Type.Name,
+			err,
+		)
+
+		return nil
+	}
+
+	// Get all the transitive ClusterWorkspaceTypes, base types first
+	cwts, err := c.transitiveTypeResolver.Resolve(leafCWT)
+	if err != nil {
+		logger.Error(err, "error resolving transitive types")
+
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized,
+			tenancyv1alpha1.WorkspaceInitializedClusterWorkspaceTypeInvalid,
+			conditionsv1alpha1.ConditionSeverityError,
+			"error resolving transitive set of ClusterWorkspaceTypes: %v",
+			err,
+		)
+
+		return nil
+	}
+
+	manifests, err := c.manifestsFor(cwts)
+	if err == nil {
+		err = c.createResources(ctx, clusterName, manifests)
+	}
+	if err != nil {
+		conditions.MarkFalse(
+			clusterWorkspace,
+			tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized,
+			tenancyv1alpha1.WorkspaceInitializedBootstrapResourcesErrors,
+			conditionsv1alpha1.ConditionSeverityError,
+			"error creating bootstrap resources: %v",
+			err,
+		)
+
+		return err // requeue
+	}
+
+	conditions.MarkTrue(clusterWorkspace, tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized)
+	clusterWorkspace.Status.Initializers = initialization.EnsureInitializerAbsent(tenancyv1alpha1.ClusterWorkspaceBootstrapResourcesInitializer, clusterWorkspace.Status.Initializers)
+
+	return nil
+}
+
+// manifestsFor returns the manifests of the bootstrap resources of the given types, in the order of
+// the types and, per ConfigMap, ordered by data key.
+func (c *controller) manifestsFor(cwts []*tenancyv1alpha1.ClusterWorkspaceType) ([]manifest, error) {
+	var manifests []manifest
+	for _, cwt := range cwts {
+		if cwt.Spec.BootstrapResources == nil {
+			continue
+		}
+
+		clusterName := logicalcluster.From(cwt)
+		ref := cwt.Spec.BootstrapResources.ConfigMap
+		cm, err := c.getConfigMap(clusterName, ref.Namespace, ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting ConfigMap %s|%s/%s of ClusterWorkspaceType %s: %w", clusterName, ref.Namespace, ref.Name, cwt.Name, err)
+		}
+
+		keys := make([]string, 0, len(cm.Data))
+		for k := range cm.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			manifests = append(manifests, manifest{
+				name: fmt.Sprintf("%s|%s/%s[%s]", clusterName, ref.Namespace, ref.Name, k),
+				raw:  []byte(cm.Data[k]),
+			})
+		}
+	}
+	return manifests, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapresources
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	admission "github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetypeexists"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/third_party/conditions/util/conditions"
+)
+
+func TestReconcile(t *testing.T) {
+	bootstrapResources := func(name string) *tenancyv1alpha1.BootstrapResources {
+		return &tenancyv1alpha1.BootstrapResources{
+			ConfigMap: tenancyv1alpha1.ConfigMapReference{Namespace: "default", Name: name},
+		}
+	}
+	types := map[string]*tenancyv1alpha1.ClusterWorkspaceType{
+		"base": {
+			ObjectMeta: metav1.ObjectMeta{Name: "base", Annotations: map[string]string{logicalcluster.AnnotationKey: "root"}},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceTypeSpec{BootstrapResources: bootstrapResources("base-manifests")},
+		},
+		"team": {
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Annotations: map[string]string{logicalcluster.AnnotationKey: "root"}},
+			Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+				Extend: tenancyv1alpha1.ClusterWorkspaceTypeExtension{
+					With: []tenancyv1alpha1.ClusterWorkspaceTypeReference{{Path: "root", Name: "base"}},
+				},
+				BootstrapResources: bootstrapResources("team-manifests"),
+			},
+		},
+		"broken": {
+			ObjectMeta: metav1.ObjectMeta{Name: "broken", Annotations: map[string]string{logicalcluster.AnnotationKey: "root"}},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceTypeSpec{BootstrapResources: bootstrapResources("missing")},
+		},
+	}
+	configMaps := map[string]*corev1.ConfigMap{
+		"base-manifests": {Data: map[string]string{"role.yaml": "role", "binding.yaml": "binding"}},
+		"team-manifests": {Data: map[string]string{"apibinding.yaml": "apibinding"}},
+	}
+
+	tests := []struct {
+		name             string
+		typeName         string
+		phase            tenancyv1alpha1.ClusterWorkspacePhaseType
+		createErr        error
+		wantErr          bool
+		wantManifests    []string
+		wantInitializer  bool
+		wantConditionMet *bool
+	}{
+		{
+			name:            "ready workspace is ignored",
+			typeName:        "team",
+			phase:           tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			wantInitializer: true,
+		},
+		{
+			name:             "manifests of extended types are created first",
+			typeName:         "team",
+			phase:            tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			wantManifests:    []string{"root|default/base-manifests[binding.yaml]", "root|default/base-manifests[role.yaml]", "root|default/team-manifests[apibinding.yaml]"},
+			wantConditionMet: pointer.Bool(true),
+		},
+		{
+			name:             "missing ConfigMap is retried",
+			typeName:         "broken",
+			phase:            tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			wantErr:          true,
+			wantInitializer:  true,
+			wantConditionMet: pointer.Bool(false),
+		},
+		{
+			name:             "creation errors are retried",
+			typeName:         "base",
+			phase:            tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			createErr:        errors.New("boom"),
+			wantErr:          true,
+			wantManifests:    []string{"root|default/base-manifests[binding.yaml]", "root|default/base-manifests[role.yaml]"},
+			wantInitializer:  true,
+			wantConditionMet: pointer.Bool(false),
+		},
+		{
+			name:             "missing type is reported",
+			typeName:         "unknown",
+			phase:            tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			wantInitializer:  true,
+			wantConditionMet: pointer.Bool(false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			c := &controller{
+				getClusterWorkspaceType: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+					if cwt, ok := types[name]; ok && clusterName == tenancyv1alpha1.RootCluster {
+						return cwt, nil
+					}
+					return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspacetypes"), name)
+				},
+				getConfigMap: func(clusterName logicalcluster.Name, namespace, name string) (*corev1.ConfigMap, error) {
+					if cm, ok := configMaps[name]; ok && clusterName == tenancyv1alpha1.RootCluster && namespace == "default" {
+						return cm, nil
+					}
+					return nil, apierrors.NewNotFound(corev1.Resource("configmaps"), name)
+				},
+				createResources: func(ctx context.Context, clusterName logicalcluster.Name, manifests []manifest) error {
+					require.Equal(t, "root:org:ws", clusterName.String())
+					for _, m := range manifests {
+						created = append(created, m.name)
+					}
+					return tt.createErr
+				},
+			}
+			c.transitiveTypeResolver = admission.NewTransitiveTypeResolver(c.getClusterWorkspaceType)
+
+			ws := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ws",
+					Annotations: map[string]string{logicalcluster.AnnotationKey: "root:org"},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{Path: "root", Name: tenancyv1alpha1.TypeName(tt.typeName)},
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tt.phase,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.ClusterWorkspaceBootstrapResourcesInitializer},
+				},
+			}
+
+			err := c.reconcile(context.Background(), ws)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantManifests, created)
+			require.Equal(t, tt.wantInitializer, len(ws.Status.Initializers) > 0, "initializer present")
+
+			if tt.wantConditionMet == nil {
+				require.Nil(t, conditions.Get(ws, tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized))
+			} else {
+				require.Equal(t, *tt.wantConditionMet, conditions.IsTrue(ws, tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized))
+				if !*tt.wantConditionMet {
+					require.Equal(t, conditionsv1alpha1.ConditionSeverityError, *conditions.GetSeverity(ws, tenancyv1alpha1.WorkspaceBootstrapResourcesInitialized))
+				}
+			}
+		})
+	}
+}
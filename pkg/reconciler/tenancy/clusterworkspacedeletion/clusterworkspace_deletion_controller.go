@@ -21,24 +21,29 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubernetesclient "k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	clusterworkspaceadmission "github.com/kcp-dev/kcp/pkg/admission/clusterworkspace"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
@@ -50,6 +55,13 @@ import (
 
 const (
 	controllerName = "kcp-clusterworkspacedeletion"
+
+	// trashedClusterWorkspaceKey holds the trashed ClusterWorkspace on its restore reservation.
+	trashedClusterWorkspaceKey = "clusterworkspace"
+
+	// restoreWaitInterval is how long to wait for a trashed ClusterWorkspace held by other finalizers
+	// to go away before it is recreated.
+	restoreWaitInterval = 5 * time.Second
 )
 
 var (
@@ -57,12 +69,18 @@ var (
 	backgroudDeletion = metav1.DeleteOptions{PropagationPolicy: &background}
 )
 
+// NewController returns a new controller which purges the content of deleted ClusterWorkspaces.
+//
+// If retentionPeriod is positive, the content of deleted ClusterWorkspaces that were ready is retained
+// for that period. Meanwhile, the ClusterWorkspace is in the Trashed phase and can be restored by setting
+// the experimental.tenancy.kcp.dev/restore annotation.
 func NewController(
 	kubeClusterClient kubernetesclient.ClusterInterface,
 	kcpClusterClient kcpclient.Interface,
 	metadataClusterClient metadata.Interface,
 	workspaceInformer tenancyinformers.ClusterWorkspaceInformer,
 	discoverResourcesFn func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error),
+	retentionPeriod time.Duration,
 ) *Controller {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
 
@@ -73,6 +91,7 @@ func NewController(
 		metadataClusterClient: metadataClusterClient,
 		workspaceLister:       workspaceInformer.Lister(),
 		deleter:               deletion.NewWorkspacedResourcesDeleter(metadataClusterClient, discoverResourcesFn),
+		retentionPeriod:       retentionPeriod,
 	}
 
 	workspaceInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
@@ -102,6 +121,8 @@ type Controller struct {
 
 	workspaceLister tenancylisters.ClusterWorkspaceLister
 	deleter         deletion.WorkspaceResourcesDeleterInterface
+
+	retentionPeriod time.Duration
 }
 
 func (c *Controller) enqueue(obj interface{}) {
@@ -182,7 +203,12 @@ func (c *Controller) process(ctx context.Context, key string) error {
 	workspace, deleteErr := c.workspaceLister.Get(key)
 	if apierrors.IsNotFound(deleteErr) {
 		logger.V(2).Info("ClusterWorkspace has been deleted")
-		return nil
+		clusterName, _, name, err := kcpcache.SplitMetaClusterNamespaceKey(key)
+		if err != nil {
+			runtime.HandleError(err)
+			return nil
+		}
+		return c.continueRestore(ctx, key, clusterName, name)
 	}
 	if deleteErr != nil {
 		runtime.HandleError(fmt.Errorf("unable to retrieve workspace %v from store: %w", key, deleteErr))
@@ -196,6 +222,27 @@ func (c *Controller) process(ctx context.Context, key string) error {
 		return nil
 	}
 
+	if !sets.NewString(workspace.Finalizers...).Has(deletion.WorkspaceFinalizer) {
+		// the content is not ours to purge (anymore), but the workspace might be being restored
+		return c.continueRestore(ctx, key, logicalcluster.From(workspace), workspace.Name)
+	}
+
+	if purgeAt, retained := retainedUntil(workspace, c.retentionPeriod, time.Now()); retained {
+		if workspace.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceRestoreAnnotationKey] == "true" {
+			return c.restoreWorkspace(ctx, key, workspace)
+		}
+
+		workspaceCopy := workspace.DeepCopy()
+		workspaceCopy.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseTrashed
+		if err := c.patchStatus(ctx, workspace, workspaceCopy); err != nil {
+			return err
+		}
+
+		logger.V(2).Info("retaining content of deleted ClusterWorkspace", "purgeAt", purgeAt)
+		c.queue.AddAfter(key, time.Until(purgeAt))
+		return nil
+	}
+
 	workspaceCopy := workspace.DeepCopy()
 
 	logger.V(2).Info("deleting ClusterWorkspace")
@@ -206,21 +253,22 @@ func (c *Controller) process(ctx context.Context, key string) error {
 		return c.finalizeWorkspace(ctx, workspaceCopy)
 	}
 
-	if err := c.patchCondition(ctx, workspace, workspaceCopy); err != nil {
+	if err := c.patchStatus(ctx, workspace, workspaceCopy); err != nil {
 		return err
 	}
 
 	return deleteErr
 }
 
-func (c *Controller) patchCondition(ctx context.Context, old, new *tenancyv1alpha1.ClusterWorkspace) error {
+func (c *Controller) patchStatus(ctx context.Context, old, new *tenancyv1alpha1.ClusterWorkspace) error {
 	logger := klog.FromContext(ctx)
-	if equality.Semantic.DeepEqual(old.Status.Conditions, new.Status.Conditions) {
+	if old.Status.Phase == new.Status.Phase && equality.Semantic.DeepEqual(old.Status.Conditions, new.Status.Conditions) {
 		return nil
 	}
 
 	oldData, err := json.Marshal(tenancyv1alpha1.ClusterWorkspace{
 		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:      old.Status.Phase,
 			Conditions: old.Status.Conditions,
 		},
 	})
@@ -234,6 +282,7 @@ func (c *Controller) patchCondition(ctx context.Context, old, new *tenancyv1alph
 			ResourceVersion: old.ResourceVersion,
 		}, // to ensure they appear in the patch as preconditions
 		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:      new.Status.Phase,
 			Conditions: new.Status.Conditions,
 		},
 	})
@@ -259,18 +308,8 @@ func (c *Controller) finalizeWorkspace(ctx context.Context, workspace *tenancyv1
 			workspace.Finalizers = append(workspace.Finalizers[:i], workspace.Finalizers[i+1:]...)
 
 			clusterName := logicalcluster.From(workspace)
-			listOpts := metav1.ListOptions{
-				LabelSelector: helper.WorkspaceLabelSelector(workspace.Name),
-			}
-
-			// TODO(hasheddan): ClusterRole and ClusterRoleBinding cleanup
-			// should be handled by garbage collection when the controller is
-			// implemented.
-			if err := c.kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoles().DeleteCollection(ctx, backgroudDeletion, listOpts); err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("could not delete clusterroles for workspace %s: %w", clusterName, err)
-			}
-			if err := c.kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoleBindings().DeleteCollection(ctx, backgroudDeletion, listOpts); err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("could not delete clusterrolebindings for workspace %s: %w", clusterName, err)
+			if err := c.deleteWorkspaceRBAC(ctx, clusterName, workspace.Name); err != nil {
+				return err
 			}
 			logger.V(2).Info("removing finalizer from ClusterWorkspace")
 			_, err := c.kcpClusterClient.TenancyV1alpha1().ClusterWorkspaces().Update(
@@ -281,3 +320,222 @@ func (c *Controller) finalizeWorkspace(ctx context.Context, workspace *tenancyv1
 
 	return nil
 }
+
+// deleteWorkspaceRBAC deletes the ClusterRoles and ClusterRoleBindings for the given workspace in its parent.
+func (c *Controller) deleteWorkspaceRBAC(ctx context.Context, clusterName logicalcluster.Name, workspaceName string) error {
+	listOpts := metav1.ListOptions{
+		LabelSelector: helper.WorkspaceLabelSelector(workspaceName),
+	}
+
+	// TODO(hasheddan): ClusterRole and ClusterRoleBinding cleanup
+	// should be handled by garbage collection when the controller is
+	// implemented.
+	if err := c.kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoles().DeleteCollection(ctx, backgroudDeletion, listOpts); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete clusterroles for workspace %s: %w", clusterName, err)
+	}
+	if err := c.kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoleBindings().DeleteCollection(ctx, backgroudDeletion, listOpts); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete clusterrolebindings for workspace %s: %w", clusterName, err)
+	}
+	return nil
+}
+
+// restoreWorkspace restores a trashed workspace. A deleted ClusterWorkspace cannot be undeleted, so its
+// finalizer is removed without purging the content of the logical cluster, and a ClusterWorkspace of the
+// same name is recreated to pick it up again. Before that, the name is reserved for the owner of the
+// trashed workspace, such that nobody else can create a workspace of that name and take over the content
+// in between.
+func (c *Controller) restoreWorkspace(ctx context.Context, key string, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+	logger := klog.FromContext(ctx)
+	clusterName := logicalcluster.From(workspace)
+
+	reservation, err := newRestoreReservation(workspace)
+	if err != nil {
+		return err
+	}
+	logger.V(2).Info("reserving the name of the trashed ClusterWorkspace to restore it")
+	reservations := c.restoreReservations()
+	_, err = reservations.Create(ctx, reservation, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		// only continue a restore of this very workspace, e.g. after failing to remove the finalizer below
+		existing, err := reservations.Get(ctx, reservation.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		reserved, err := trashedClusterWorkspace(existing, clusterName, workspace.Name)
+		if err != nil {
+			return err
+		}
+		if reserved.UID != workspace.UID {
+			return fmt.Errorf("name of ClusterWorkspace %s|%s is already reserved for the restore of ClusterWorkspace with UID %s", clusterName, workspace.Name, reserved.UID)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to reserve the name of ClusterWorkspace %s|%s: %w", clusterName, workspace.Name, err)
+	}
+
+	workspaceCopy := workspace.DeepCopy()
+	workspaceCopy.Finalizers = nil
+	for _, f := range workspace.Finalizers {
+		if f != deletion.WorkspaceFinalizer {
+			workspaceCopy.Finalizers = append(workspaceCopy.Finalizers, f)
+		}
+	}
+	logger.V(2).Info("removing finalizer from trashed ClusterWorkspace to restore it")
+	if _, err := c.kcpClusterClient.TenancyV1alpha1().ClusterWorkspaces().Update(logicalcluster.WithCluster(ctx, clusterName), workspaceCopy, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	return c.continueRestore(ctx, key, clusterName, workspace.Name)
+}
+
+// continueRestore recreates a restored workspace from its restore reservation, if there is one. While the
+// trashed ClusterWorkspace is still around, e.g. because of other finalizers, it waits for it to go away.
+// If the workspace cannot be recreated until the end of the retention period, its content is purged.
+func (c *Controller) continueRestore(ctx context.Context, key string, clusterName logicalcluster.Name, name string) error {
+	logger := klog.FromContext(ctx)
+	reservations := c.restoreReservations()
+	reservation, err := reservations.Get(ctx, clusterworkspaceadmission.RestoreReservationName(clusterName, name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	trashed, err := trashedClusterWorkspace(reservation, clusterName, name)
+	if err != nil {
+		return err
+	}
+
+	if purgeAt, retained := retainedUntil(trashed, c.retentionPeriod, time.Now()); !retained {
+		logger.Info("giving up restoring ClusterWorkspace, purging its content", "purgeAt", purgeAt)
+		if err := c.deleteWorkspaceRBAC(ctx, clusterName, name); err != nil {
+			return err
+		}
+		if err := c.deleter.Delete(ctx, trashed); err != nil {
+			return err
+		}
+		return c.releaseRestoreReservation(ctx, reservations, reservation)
+	}
+
+	existing, err := c.kcpClusterClient.TenancyV1alpha1().ClusterWorkspaces().Get(logicalcluster.WithCluster(ctx, clusterName), name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		logger.V(2).Info("recreating restored ClusterWorkspace")
+		if _, err := c.kcpClusterClient.TenancyV1alpha1().ClusterWorkspaces().Create(logicalcluster.WithCluster(ctx, clusterName), restoredClusterWorkspace(trashed), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to recreate restored ClusterWorkspace %s|%s: %w", clusterName, name, err)
+		}
+	case err != nil:
+		return err
+	case existing.UID == trashed.UID:
+		logger.V(2).Info("waiting for trashed ClusterWorkspace to go away to restore it")
+		c.queue.AddAfter(key, restoreWaitInterval)
+		return nil
+	default:
+		// admission only allows the owner of the trashed workspace to create a workspace of the reserved name
+		logger.V(2).Info("ClusterWorkspace has been recreated by its owner")
+	}
+
+	return c.releaseRestoreReservation(ctx, reservations, reservation)
+}
+
+// restoreReservations returns the client for the restore reservations, which live in a logical cluster
+// that only the system can write to.
+func (c *Controller) restoreReservations() corev1client.ConfigMapInterface {
+	return c.kubeClusterClient.Cluster(clusterworkspaceadmission.RestoreReservationCluster).CoreV1().ConfigMaps(clusterworkspaceadmission.RestoreReservationNamespace)
+}
+
+func (c *Controller) releaseRestoreReservation(ctx context.Context, reservations corev1client.ConfigMapInterface, reservation *corev1.ConfigMap) error {
+	klog.FromContext(ctx).V(2).Info("releasing restore reservation")
+	if err := reservations.Delete(ctx, reservation.Name, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &reservation.UID}}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// retainedUntil returns when the content of the given deleted workspace is purged, and whether
+// it is still retained at the given time. Only the content of ready workspaces is retained.
+func retainedUntil(workspace *tenancyv1alpha1.ClusterWorkspace, retentionPeriod time.Duration, now time.Time) (time.Time, bool) {
+	if retentionPeriod <= 0 || workspace.DeletionTimestamp.IsZero() {
+		return time.Time{}, false
+	}
+
+	switch workspace.Status.Phase {
+	case tenancyv1alpha1.ClusterWorkspacePhaseReady, tenancyv1alpha1.ClusterWorkspacePhaseTrashed:
+	default:
+		return time.Time{}, false
+	}
+
+	purgeAt := workspace.DeletionTimestamp.Add(retentionPeriod)
+	return purgeAt, now.Before(purgeAt)
+}
+
+// newRestoreReservation returns the ConfigMap reserving the name of the given trashed workspace for its
+// owner while it is restored. It lives in the system:shard logical cluster, out of reach of tenants.
+// It records the trashed ClusterWorkspace to recreate the workspace from, or to purge its content from if the
+// workspace cannot be recreated.
+func newRestoreReservation(workspace *tenancyv1alpha1.ClusterWorkspace) (*corev1.ConfigMap, error) {
+	clusterName := logicalcluster.From(workspace)
+	raw, err := json.Marshal(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ClusterWorkspace %s|%s: %w", clusterName, workspace.Name, err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: clusterworkspaceadmission.RestoreReservationNamespace,
+			Name:      clusterworkspaceadmission.RestoreReservationName(clusterName, workspace.Name),
+		},
+		Data: map[string]string{
+			clusterworkspaceadmission.RestoreReservationClusterKey: clusterName.String(),
+			clusterworkspaceadmission.RestoreReservationNameKey:    workspace.Name,
+			clusterworkspaceadmission.RestoreReservationOwnerKey:   workspace.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey],
+			trashedClusterWorkspaceKey:                             string(raw),
+		},
+	}, nil
+}
+
+// trashedClusterWorkspace returns the trashed ClusterWorkspace recorded on the given restore reservation,
+// after checking that it reserves the name of the given workspace.
+func trashedClusterWorkspace(reservation *corev1.ConfigMap, clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+	if !clusterworkspaceadmission.IsRestoreReservationFor(reservation, clusterName, name) {
+		return nil, fmt.Errorf("restore reservation %s does not reserve ClusterWorkspace %s|%s", reservation.Name, clusterName, name)
+	}
+	var workspace tenancyv1alpha1.ClusterWorkspace
+	if err := json.Unmarshal([]byte(reservation.Data[trashedClusterWorkspaceKey]), &workspace); err != nil {
+		return nil, fmt.Errorf("invalid restore reservation %s: %w", reservation.Name, err)
+	}
+	if logicalcluster.From(&workspace) != clusterName || workspace.Name != name {
+		return nil, fmt.Errorf("restore reservation %s records ClusterWorkspace %s|%s instead of %s|%s", reservation.Name, logicalcluster.From(&workspace), workspace.Name, clusterName, name)
+	}
+	return &workspace, nil
+}
+
+// restoredClusterWorkspace returns the ClusterWorkspace to recreate for the given trashed one. It is pinned
+// to the shard of the trashed workspace because that is where the content lives.
+func restoredClusterWorkspace(workspace *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+	restored := &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        workspace.Name,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Spec: *workspace.Spec.DeepCopy(),
+	}
+
+	for k, v := range workspace.Labels {
+		if k == tenancyv1alpha1.ClusterWorkspacePhaseLabel || strings.HasPrefix(k, tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix) {
+			continue
+		}
+		restored.Labels[k] = v
+	}
+	for k, v := range workspace.Annotations {
+		if k == tenancyv1alpha1.ExperimentalClusterWorkspaceRestoreAnnotationKey {
+			continue
+		}
+		restored.Annotations[k] = v
+	}
+
+	if shard := workspace.Status.Location.Current; shard != "" {
+		restored.Spec.Shard = &tenancyv1alpha1.ShardConstraints{Name: shard}
+	}
+
+	return restored
+}
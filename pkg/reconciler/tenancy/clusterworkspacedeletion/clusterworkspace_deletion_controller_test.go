@@ -0,0 +1,352 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspacedeletion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kcpcache "github.com/kcp-dev/apimachinery/pkg/cache"
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	clusterworkspaceadmission "github.com/kcp-dev/kcp/pkg/admission/clusterworkspace"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpfake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	tenancylisters "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacedeletion/deletion"
+)
+
+func TestRetainedUntil(t *testing.T) {
+	deleted := metav1.NewTime(time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC))
+	week := 7 * 24 * time.Hour
+
+	tests := []struct {
+		name            string
+		phase           tenancyv1alpha1.ClusterWorkspacePhaseType
+		notDeleted      bool
+		retentionPeriod time.Duration
+		now             time.Time
+		wantPurgeAt     time.Time
+		wantRetained    bool
+	}{
+		{
+			name:            "ready workspace is retained",
+			phase:           tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			retentionPeriod: week,
+			now:             deleted.Add(time.Hour),
+			wantPurgeAt:     deleted.Add(week),
+			wantRetained:    true,
+		},
+		{
+			name:            "trashed workspace is retained",
+			phase:           tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+			retentionPeriod: week,
+			now:             deleted.Add(week - time.Second),
+			wantPurgeAt:     deleted.Add(week),
+			wantRetained:    true,
+		},
+		{
+			name:            "trashed workspace is purged after the retention period",
+			phase:           tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+			retentionPeriod: week,
+			now:             deleted.Add(week),
+			wantPurgeAt:     deleted.Add(week),
+		},
+		{
+			name:            "initializing workspace is purged immediately",
+			phase:           tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			retentionPeriod: week,
+			now:             deleted.Add(time.Hour),
+		},
+		{
+			name:  "no retention without retention period",
+			phase: tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			now:   deleted.Add(time.Hour),
+		},
+		{
+			name:            "workspace not being deleted is not retained",
+			phase:           tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			notDeleted:      true,
+			retentionPeriod: week,
+			now:             deleted.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "ws", DeletionTimestamp: &deleted},
+				Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Phase: tt.phase},
+			}
+			if tt.notDeleted {
+				ws.DeletionTimestamp = nil
+			}
+
+			purgeAt, retained := retainedUntil(ws, tt.retentionPeriod, tt.now)
+			require.Equal(t, tt.wantRetained, retained, "retained")
+			require.Equal(t, tt.wantPurgeAt, purgeAt)
+		})
+	}
+}
+
+func TestRestoredClusterWorkspace(t *testing.T) {
+	deleted := metav1.Now()
+	trashed := &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "ws",
+			UID:               "uid",
+			ResourceVersion:   "42",
+			DeletionTimestamp: &deleted,
+			Finalizers:        []string{"tenancy.kcp.dev/workspace-finalizer"},
+			Labels: map[string]string{
+				"team": "a",
+				tenancyv1alpha1.ClusterWorkspacePhaseLabel:                     "Trashed",
+				tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix + "abc": "root:foo",
+			},
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey:                                     "root:org",
+				tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey:   `{"username":"alice"}`,
+				tenancyv1alpha1.ExperimentalClusterWorkspaceRestoreAnnotationKey: "true",
+			},
+		},
+		Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+			Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{Name: "universal", Path: "root"},
+			Shard: &tenancyv1alpha1.ShardConstraints{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"region": "eu"}},
+			},
+		},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:    tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+			Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "shard-1"},
+			BaseURL:  "https://shard-1/clusters/root:org:ws",
+		},
+	}
+
+	require.Equal(t, &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "ws",
+			Labels: map[string]string{"team": "a"},
+			Annotations: map[string]string{
+				logicalcluster.AnnotationKey:                                   "root:org",
+				tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey: `{"username":"alice"}`,
+			},
+		},
+		Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+			Type:  tenancyv1alpha1.ClusterWorkspaceTypeReference{Name: "universal", Path: "root"},
+			Shard: &tenancyv1alpha1.ShardConstraints{Name: "shard-1"},
+		},
+	}, restoredClusterWorkspace(trashed))
+}
+
+func TestProcessRestore(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	owner := `{"username":"alice"}`
+	trashed := func(deletedAgo time.Duration, finalizers ...string) *tenancyv1alpha1.ClusterWorkspace {
+		deleted := metav1.NewTime(time.Now().Add(-deletedAgo))
+		return &tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "ws",
+				UID:               "uid",
+				DeletionTimestamp: &deleted,
+				Finalizers:        append([]string{deletion.WorkspaceFinalizer}, finalizers...),
+				Annotations: map[string]string{
+					logicalcluster.AnnotationKey:                                     "root:org",
+					tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey:   owner,
+					tenancyv1alpha1.ExperimentalClusterWorkspaceRestoreAnnotationKey: "true",
+				},
+			},
+			Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+				Type: tenancyv1alpha1.ClusterWorkspaceTypeReference{Name: "universal", Path: "root"},
+			},
+			Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+				Phase:    tenancyv1alpha1.ClusterWorkspacePhaseTrashed,
+				Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "shard-1"},
+			},
+		}
+	}
+	reservationFor := func(t *testing.T, workspace *tenancyv1alpha1.ClusterWorkspace) runtime.Object {
+		reservation, err := newRestoreReservation(workspace)
+		require.NoError(t, err)
+		return reservation
+	}
+
+	tests := []struct {
+		name           string
+		workspace      *tenancyv1alpha1.ClusterWorkspace
+		reservation    func(t *testing.T) runtime.Object
+		wantErr        string
+		wantRestored   bool
+		wantTrashed    bool
+		wantFinalizers []string
+		wantReserved   bool
+		wantPurged     bool
+	}{
+		{
+			name:         "trashed workspace is reserved, recreated and released",
+			workspace:    trashed(time.Hour),
+			wantRestored: true,
+		},
+		{
+			name:           "trashed workspace held by other finalizers keeps the name reserved",
+			workspace:      trashed(time.Hour, "other"),
+			wantTrashed:    true,
+			wantFinalizers: []string{"other"},
+			wantReserved:   true,
+		},
+		{
+			name:      "trashed workspace is not restored when its name is reserved for another workspace",
+			workspace: trashed(time.Hour),
+			reservation: func(t *testing.T) runtime.Object {
+				other := trashed(time.Hour)
+				other.UID = "other-uid"
+				return reservationFor(t, other)
+			},
+			wantErr:        "is already reserved for the restore of ClusterWorkspace with UID other-uid",
+			wantTrashed:    true,
+			wantFinalizers: []string{deletion.WorkspaceFinalizer},
+			wantReserved:   true,
+		},
+		{
+			name:      "trashed workspace continues to be restored with its own reservation",
+			workspace: trashed(time.Hour),
+			reservation: func(t *testing.T) runtime.Object {
+				return reservationFor(t, trashed(time.Hour))
+			},
+			wantRestored: true,
+		},
+		{
+			name: "deleted workspace is recreated from its reservation",
+			reservation: func(t *testing.T) runtime.Object {
+				return reservationFor(t, trashed(time.Hour))
+			},
+			wantRestored: true,
+		},
+		{
+			name: "content is purged when the workspace is not recreated within the retention period",
+			reservation: func(t *testing.T) runtime.Object {
+				return reservationFor(t, trashed(2*week))
+			},
+			wantPurged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kcpObjects []runtime.Object
+			indexer := cache.NewIndexer(kcpcache.MetaClusterNamespaceKeyFunc, cache.Indexers{})
+			if tt.workspace != nil {
+				require.NoError(t, indexer.Add(tt.workspace))
+				kcpObjects = append(kcpObjects, tt.workspace)
+			}
+			kcpClient := kcpfake.NewSimpleClientset(kcpObjects...)
+			// the apiserver deletes objects with deletion timestamp when their last finalizer is removed
+			kcpClient.PrependReactor("update", "clusterworkspaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				ws := action.(clienttesting.UpdateAction).GetObject().(*tenancyv1alpha1.ClusterWorkspace)
+				if ws.DeletionTimestamp.IsZero() || len(ws.Finalizers) > 0 {
+					return false, nil, nil
+				}
+				return true, ws, kcpClient.Tracker().Delete(tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces"), "", ws.Name)
+			})
+
+			var systemObjects []runtime.Object
+			if tt.reservation != nil {
+				systemObjects = append(systemObjects, tt.reservation(t))
+			}
+			parentKubeClient := kubefake.NewSimpleClientset()
+			systemKubeClient := kubefake.NewSimpleClientset(systemObjects...)
+			kubeClusterClient := mockKubeClusterClient(func(cluster logicalcluster.Name) kubernetes.Interface {
+				if cluster == clusterworkspaceadmission.RestoreReservationCluster {
+					return systemKubeClient
+				}
+				return parentKubeClient
+			})
+
+			deleter := &fakeDeleter{}
+			queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+			defer queue.ShutDown()
+			c := &Controller{
+				queue:             queue,
+				kubeClusterClient: kubeClusterClient,
+				kcpClusterClient:  kcpClient,
+				workspaceLister:   tenancylisters.NewClusterWorkspaceLister(indexer),
+				deleter:           deleter,
+				retentionPeriod:   week,
+			}
+
+			key := kcpcache.ToClusterAwareKey("root:org", "", "ws")
+			if err := c.process(context.Background(), key); tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			ws, err := kcpClient.TenancyV1alpha1().ClusterWorkspaces().Get(context.Background(), "ws", metav1.GetOptions{})
+			switch {
+			case tt.wantRestored:
+				require.NoError(t, err)
+				require.Empty(t, ws.UID, "expected a recreated ClusterWorkspace")
+				require.True(t, ws.DeletionTimestamp.IsZero())
+				require.Equal(t, owner, ws.Annotations[tenancyv1alpha1.ExperimentalClusterWorkspaceOwnerAnnotationKey])
+				require.Equal(t, "shard-1", ws.Spec.Shard.Name)
+			case tt.wantTrashed:
+				require.NoError(t, err)
+				require.Equal(t, "uid", string(ws.UID))
+				require.Equal(t, tt.wantFinalizers, ws.Finalizers)
+			default:
+				require.True(t, apierrors.IsNotFound(err), "expected no ClusterWorkspace, got %v", err)
+			}
+
+			reservationName := clusterworkspaceadmission.RestoreReservationName(logicalcluster.New("root:org"), "ws")
+			_, err = systemKubeClient.CoreV1().ConfigMaps(clusterworkspaceadmission.RestoreReservationNamespace).Get(context.Background(), reservationName, metav1.GetOptions{})
+			if tt.wantReserved {
+				require.NoError(t, err)
+			} else {
+				require.True(t, apierrors.IsNotFound(err), "expected restore reservation to be released, got %v", err)
+			}
+
+			if tt.wantPurged {
+				require.Equal(t, []string{"ws"}, deleter.deleted)
+			} else {
+				require.Empty(t, deleter.deleted)
+			}
+		})
+	}
+}
+
+type fakeDeleter struct {
+	deleted []string
+}
+
+func (d *fakeDeleter) Delete(ctx context.Context, ws *tenancyv1alpha1.ClusterWorkspace) error {
+	d.deleted = append(d.deleted, ws.Name)
+	return nil
+}
+
+type mockKubeClusterClient func(cluster logicalcluster.Name) kubernetes.Interface
+
+func (m mockKubeClusterClient) Cluster(cluster logicalcluster.Name) kubernetes.Interface {
+	return m(cluster)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspacedeletion
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.RetentionPeriod, "workspace-retention-period", o.RetentionPeriod, "Amount of time the content of a deleted workspace is retained in the Trashed phase before it is purged. "+
+		"Until then, the workspace can be restored. 0 purges deleted workspaces immediately.")
+	return o
+}
+
+type Options struct {
+	RetentionPeriod time.Duration
+}
+
+func (o *Options) Validate() error {
+	if o.RetentionPeriod < 0 {
+		return fmt.Errorf("--workspace-retention-period must be >=0 (%s)", o.RetentionPeriod)
+	}
+	return nil
+}
@@ -59,6 +59,14 @@ func NewController(
 
 	c := &controller{
 		queue: queue,
+		enqueueAfter: func(placement *schedulingv1alpha1.Placement, duration time.Duration) {
+			key, err := kcpcache.MetaClusterNamespaceKeyFunc(placement)
+			if err != nil {
+				runtime.HandleError(err)
+				return
+			}
+			queue.AddAfter(key, duration)
+		},
 
 		kcpClusterClient: kcpClusterClient,
 
@@ -146,7 +154,8 @@ func NewController(
 
 // controller
 type controller struct {
-	queue workqueue.RateLimitingInterface
+	queue        workqueue.RateLimitingInterface
+	enqueueAfter func(*schedulingv1alpha1.Placement, time.Duration)
 
 	kcpClusterClient kcpclient.Interface
 
@@ -48,6 +48,7 @@ func (c *controller) reconcile(ctx context.Context, placement *schedulingv1alpha
 			listSyncTarget: c.listSyncTarget,
 			getLocation:    c.getLocation,
 			patchPlacement: c.patchPlacement,
+			enqueueAfter:   c.enqueueAfter,
 		},
 	}
 
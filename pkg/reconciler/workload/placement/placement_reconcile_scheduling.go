@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"math/rand"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
@@ -36,11 +37,13 @@ import (
 
 // placementSchedulingReconciler schedules placments according to the selected locations.
 // It considers only valid SyncTargets and updates the internal.workload.kcp.dev/synctarget
-// annotation with the selected one on the placement object.
+// annotation with the selected one on the placement object. New placements are only scheduled to
+// schedulable SyncTargets, while existing ones stay on their SyncTarget until it evicts them.
 type placementSchedulingReconciler struct {
 	listSyncTarget func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.SyncTarget, error)
 	getLocation    func(clusterName logicalcluster.Name, name string) (*schedulingv1alpha1.Location, error)
 	patchPlacement func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*schedulingv1alpha1.Placement, error)
+	enqueueAfter   func(*schedulingv1alpha1.Placement, time.Duration)
 }
 
 func (r *placementSchedulingReconciler) reconcile(ctx context.Context, placement *schedulingv1alpha1.Placement) (reconcileStatus, *schedulingv1alpha1.Placement, error) {
@@ -56,35 +59,40 @@ func (r *placementSchedulingReconciler) reconcile(ctx context.Context, placement
 		return reconcileStatusStop, placement, err
 	}
 
-	// no valid synctarget, clean the annotation.
-	if foundScheduled && len(syncTargets) == 0 {
-		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = nil
-		updated, err := r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
-		return reconcileStatusContinue, updated, err
-	}
-
-	// 2. do nothing if scheduled cluster is in the valid clusters
-	if foundScheduled && len(syncTargets) > 0 {
+	// 3. do nothing if scheduled cluster is in the valid clusters. Unschedulable sync targets
+	// keep their placements until they are evicted.
+	if foundScheduled {
 		for _, syncTarget := range syncTargets {
 			syncTargetKey := workloadv1alpha1.ToSyncTargetKey(logicalcluster.From(syncTarget), syncTarget.Name)
 			if syncTargetKey != currentScheduled {
 				continue
 			}
+			if evictAfter := syncTarget.Spec.EvictAfter; evictAfter != nil {
+				r.enqueueAfter(placement, time.Until(evictAfter.Time))
+			}
 			return reconcileStatusContinue, placement, nil
 		}
 	}
 
-	// 3. randomly select one as the scheduled cluster
+	// 4. randomly select one of the schedulable clusters as the scheduled cluster
 	// TODO(qiujian16): we currently schedule each in each location independently. It cannot guarantee 1 cluster is scheduled per location
 	// when the same synctargets are in multiple locations, we need to rethink whether we need a better algorithm or we need location
 	// to be exclusive.
-	if len(syncTargets) > 0 {
-		scheduledSyncTarget := syncTargets[rand.Intn(len(syncTargets))]
+	schedulable := locationreconciler.FilterSchedulable(syncTargets)
+	if len(schedulable) > 0 {
+		scheduledSyncTarget := schedulable[rand.Intn(len(schedulable))]
 		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = workloadv1alpha1.ToSyncTargetKey(syncTargetClusterName, scheduledSyncTarget.Name)
 		updated, err := r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
 		return reconcileStatusContinue, updated, err
 	}
 
+	// no schedulable synctarget, clean the annotation.
+	if foundScheduled {
+		expectedAnnotations[workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey] = nil
+		updated, err := r.patchPlacementAnnotation(ctx, clusterName, placement, expectedAnnotations)
+		return reconcileStatusContinue, updated, err
+	}
+
 	return reconcileStatusContinue, placement, nil
 }
 
@@ -116,7 +124,7 @@ func (r *placementSchedulingReconciler) getAllValidSyncTargetsForPlacement(clust
 		return locationWorkspace, nil, err
 	}
 
-	// find all the valid sync targets.
+	// find all the valid sync targets, including unschedulable ones that are not evicting yet.
 	validClusters := locationreconciler.FilterNonEvicting(locationreconciler.FilterReady(locationClusters))
 
 	return locationWorkspace, validClusters, nil
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -45,6 +46,7 @@ func TestSchedulingReconcile(t *testing.T) {
 		syncTargets []*workloadv1alpha1.SyncTarget
 
 		wantPatch           bool
+		wantRequeue         bool
 		expectedAnnotations map[string]string
 	}{
 		{
@@ -93,6 +95,59 @@ func TestSchedulingReconcile(t *testing.T) {
 				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
 			},
 		},
+		{
+			name:        "cordoned synctarget keeps its placement",
+			placement:   newPlacement("test", "test-location", "c1"),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{cordoned(newSyncTarget("c1", true)), newSyncTarget("c2", true)},
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aQtdeEWVcqU7h7AKnYMm3KRQ96U4oU2W04yeOa",
+			},
+		},
+		{
+			name:        "do not schedule to cordoned synctarget",
+			placement:   newPlacement("test", "test-location", ""),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{cordoned(newSyncTarget("c1", true)), newSyncTarget("c2", true)},
+			wantPatch:   true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
+			},
+		},
+		{
+			name:        "no schedulable synctarget",
+			placement:   newPlacement("test", "test-location", ""),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{cordoned(newSyncTarget("c1", true))},
+		},
+		{
+			name:        "requeue until synctarget evicts",
+			placement:   newPlacement("test", "test-location", "c1"),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{draining(newSyncTarget("c1", true), time.Hour), newSyncTarget("c2", true)},
+			wantRequeue: true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aQtdeEWVcqU7h7AKnYMm3KRQ96U4oU2W04yeOa",
+			},
+		},
+		{
+			name:        "reschedule drained synctarget",
+			placement:   newPlacement("test", "test-location", "c1"),
+			location:    newLocation("test-location"),
+			syncTargets: []*workloadv1alpha1.SyncTarget{draining(newSyncTarget("c1", true), -time.Minute), newSyncTarget("c2", true)},
+			wantPatch:   true,
+			expectedAnnotations: map[string]string{
+				workloadv1alpha1.InternalSyncTargetPlacementAnnotationKey: "aPkhvUbGK0xoZIjMnM2pA0AuV1g7i4tBwxu5m4",
+			},
+		},
+		{
+			name:                "unschedule drained synctarget",
+			placement:           newPlacement("test", "test-location", "c1"),
+			location:            newLocation("test-location"),
+			syncTargets:         []*workloadv1alpha1.SyncTarget{draining(newSyncTarget("c1", true), -time.Minute)},
+			wantPatch:           true,
+			expectedAnnotations: map[string]string{},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -122,15 +177,22 @@ func TestSchedulingReconcile(t *testing.T) {
 				}
 				return &patchedPlacement, err
 			}
+			var requeued bool
+			enqueueAfter := func(placement *schedulingv1alpha1.Placement, duration time.Duration) {
+				requeued = true
+				require.True(t, duration > 0 && duration <= time.Hour, "unexpected requeue duration %s", duration)
+			}
 			reconciler := &placementSchedulingReconciler{
 				listSyncTarget: listSyncTarget,
 				getLocation:    getLocation,
 				patchPlacement: patchPlacement,
+				enqueueAfter:   enqueueAfter,
 			}
 
 			_, updated, err := reconciler.reconcile(context.TODO(), testCase.placement)
 			require.NoError(t, err)
 			require.Equal(t, testCase.wantPatch, patched)
+			require.Equal(t, testCase.wantRequeue, requeued)
 			require.Equal(t, testCase.expectedAnnotations, updated.Annotations)
 		})
 	}
@@ -184,3 +246,14 @@ func newSyncTarget(name string, ready bool) *workloadv1alpha1.SyncTarget {
 
 	return syncTarget
 }
+
+func cordoned(syncTarget *workloadv1alpha1.SyncTarget) *workloadv1alpha1.SyncTarget {
+	syncTarget.Spec.Unschedulable = true
+	return syncTarget
+}
+
+func draining(syncTarget *workloadv1alpha1.SyncTarget, evictAfter time.Duration) *workloadv1alpha1.SyncTarget {
+	syncTarget.Spec.Unschedulable = true
+	syncTarget.Spec.EvictAfter = &metav1.Time{Time: time.Now().Add(evictAfter)}
+	return syncTarget
+}
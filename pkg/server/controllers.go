@@ -65,6 +65,7 @@ import (
 	schedulinglocationstatus "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
 	schedulingplacement "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/placement"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/bootstrap"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/bootstrapresources"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacedeletion"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspaceshard"
@@ -322,6 +323,7 @@ func (s *Server) installWorkspaceDeletionController(ctx context.Context, config
 		metadataClusterClient,
 		s.KcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
 		discoverResourcesFn,
+		s.Options.Controllers.WorkspaceDeletion.RetentionPeriod,
 	)
 
 	return s.AddPostStartHook(postStartHookName(controllerName), func(hookContext genericapiserver.PostStartHookContext) error {
@@ -591,6 +593,7 @@ func (s *Server) installAPIBindingController(ctx context.Context, config *rest.C
 		dynamicClusterClient,
 		ddsif,
 		s.KcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		s.KubeSharedInformerFactory.Core().V1().Namespaces(),
 	)
 	if err != nil {
 		return err
@@ -696,6 +699,68 @@ func (s *Server) installAPIBinderController(ctx context.Context, config *rest.Co
 	})
 }
 
+func (s *Server) installBootstrapResourcesInitializerController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	// Clients used to create the bootstrap resources within the initializing workspace
+	config = rest.CopyConfig(config)
+	kcpclienthelper.SetMultiClusterRoundTripper(config)
+	config = rest.AddUserAgent(config, bootstrapresources.ControllerName)
+	// TODO(ncdc): support standalone vw server when --shard-virtual-workspace-url is set
+	config.Host += initializingworkspacesbuilder.URLFor(tenancyv1alpha1.ClusterWorkspaceBootstrapResourcesInitializer)
+	initializingWorkspacesKcpClusterClient, err := kcpclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	initializingWorkspacesDynamicClusterClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	// Wildcard client used for informers
+	informerCfg := rest.CopyConfig(config)
+	kcpclienthelper.SetCluster(informerCfg, logicalcluster.Wildcard)
+	informerClient, err := kcpclient.NewForConfig(informerCfg)
+	if err != nil {
+		return err
+	}
+
+	// This informer factory is created here because it is specifically against the initializing workspaces virtual
+	// workspace.
+	initializingWorkspacesKcpInformers := kcpexternalversions.NewSharedInformerFactoryWithOptions(
+		informerClient,
+		resyncPeriod,
+		kcpexternalversions.WithExtraClusterScopedIndexers(indexers.ClusterScoped()),
+		kcpexternalversions.WithExtraNamespaceScopedIndexers(indexers.NamespaceScoped()),
+	)
+
+	c, err := bootstrapresources.NewController(
+		config,
+		initializingWorkspacesDynamicClusterClient,
+		initializingWorkspacesKcpClusterClient,
+		initializingWorkspacesKcpInformers.Tenancy().V1alpha1().ClusterWorkspaces(),
+		s.KcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceTypes(),
+		s.KubeSharedInformerFactory.Core().V1().ConfigMaps(),
+		sets.NewString(s.Options.Extra.BatteriesIncluded...),
+	)
+	if err != nil {
+		return err
+	}
+
+	return server.AddPostStartHook(postStartHookName(bootstrapresources.ControllerName), func(hookContext genericapiserver.PostStartHookContext) error {
+		logger := klog.FromContext(ctx).WithValues("postStartHook", postStartHookName(bootstrapresources.ControllerName))
+
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			logger.Error(err, "failed to finish post-start-hook")
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		initializingWorkspacesKcpInformers.Start(hookContext.StopCh)
+		initializingWorkspacesKcpInformers.WaitForCacheSync(hookContext.StopCh)
+
+		go c.Start(goContext(hookContext), 2)
+		return nil
+	})
+}
+
 func (s *Server) installAPIExportController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	controllerName := "kcp-apiexport-controller"
 	config = rest.CopyConfig(config)
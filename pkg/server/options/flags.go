@@ -177,6 +177,7 @@ var (
 		"run-virtual-workspaces",                 // Run the virtual workspaces apiservers in-process
 		"unsupported-run-individual-controllers", // Run individual controllers in-process. The controller names can change at any time.
 		"sync-target-heartbeat-threshold",        // Amount of time to wait for a successful heartbeat before marking the cluster as not ready.
+		"workspace-retention-period",             // Amount of time the content of a deleted workspace is retained in the Trashed phase before it is purged. Until then, the workspace can be restored. 0 purges deleted workspaces immediately.
 
 		// KCP Cache Server flags
 		"cache-url",        // A URL address of a cache server associated with this instance (default https://localhost:6443)
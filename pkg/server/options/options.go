@@ -182,7 +182,7 @@ func (o *Options) rawFlags() cliflag.NamedFlagSets {
 		`A list of batteries included (= default objects that might be unwanted in production, but are very helpful in trying out kcp or for development). These are the possible values: %s.
 
 - cluster-workspace-types: creates "organization" and "team" ClusterWorkspaceTypes in the root workspace.
-- root-compute-workspace:  create a root:compute workspace, and kubernetes APIExport in it for deployments/services/ingresses
+- root-compute-workspace:  create a root:compute workspace, and kubernetes APIExport in it for deployments/statefulsets/services/ingresses
 - user:                    creates an additional non-admin user and context named "user" in the admin.kubeconfig
 
 Prefixing with - or + means to remove from the default set or add to the default set.`,
@@ -447,6 +447,12 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
+	if s.Options.Controllers.EnableAll || enabled.Has("bootstrapresources") {
+		if err := s.installBootstrapResourcesInitializerController(ctx, controllerConfig, delegationChainHead); err != nil {
+			return err
+		}
+	}
+
 	if kcpfeatures.DefaultFeatureGate.Enabled(kcpfeatures.LocationAPI) {
 		if s.Options.Controllers.EnableAll || enabled.Has("scheduling") {
 			if err := s.installWorkloadNamespaceScheduler(ctx, controllerConfig, delegationChainHead); err != nil {
@@ -34,6 +34,12 @@ import (
 type ListSecretFunc func(clusterName logicalcluster.Name, namespace string) ([]*unstructured.Unstructured, error)
 
 type DeploymentMutator struct {
+	podSpecMutator
+}
+
+// podSpecMutator mutates the pod templates of workloads such that the pods access the kcp API
+// instead of the physical cluster API, and resolve services through the workspace DNS.
+type podSpecMutator struct {
 	upstreamURL                  *url.URL
 	listSecrets                  ListSecretFunc
 	syncTargetLogicalClusterName logicalcluster.Name
@@ -50,10 +56,12 @@ func (dm *DeploymentMutator) GVR() schema.GroupVersionResource {
 
 func NewDeploymentMutator(upstreamURL *url.URL, secretLister ListSecretFunc, syncTargetLogicalClusterName logicalcluster.Name, dnsIP string) *DeploymentMutator {
 	return &DeploymentMutator{
-		upstreamURL:                  upstreamURL,
-		listSecrets:                  secretLister,
-		syncTargetLogicalClusterName: syncTargetLogicalClusterName,
-		dnsIP:                        dnsIP,
+		podSpecMutator: podSpecMutator{
+			upstreamURL:                  upstreamURL,
+			listSecrets:                  secretLister,
+			syncTargetLogicalClusterName: syncTargetLogicalClusterName,
+			dnsIP:                        dnsIP,
+		},
 	}
 }
 
@@ -66,16 +74,31 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	if err != nil {
 		return err
 	}
-	upstreamLogicalName := logicalcluster.From(obj)
 
-	templateSpec := &deployment.Spec.Template.Spec
+	if err := dm.mutate(logicalcluster.From(obj), deployment.Namespace, &deployment.Spec.Template.Spec); err != nil {
+		return err
+	}
+
+	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+	if err != nil {
+		return err
+	}
+
+	// Set the changes back into the obj.
+	obj.SetUnstructuredContent(unstructured)
+
+	return nil
+}
 
+// mutate applies the mutator changes to the pod template spec of a workload in the given
+// upstream workspace and namespace.
+func (pm *podSpecMutator) mutate(upstreamLogicalName logicalcluster.Name, namespace string, templateSpec *corev1.PodSpec) error {
 	desiredServiceAccountName := "default"
 	if templateSpec.ServiceAccountName != "" && templateSpec.ServiceAccountName != "default" {
 		desiredServiceAccountName = templateSpec.ServiceAccountName
 	}
 
-	secretList, err := dm.listSecrets(upstreamLogicalName, deployment.Namespace)
+	secretList, err := pm.listSecrets(upstreamLogicalName, namespace)
 	if err != nil {
 		return fmt.Errorf("error listing secrets for workspace %s: %w", upstreamLogicalName.String(), err)
 	}
@@ -103,7 +126,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	}
 
 	if desiredSecretName == "" {
-		return fmt.Errorf("couldn't find a token upstream for the serviceaccount %s/%s in workspace %s", desiredServiceAccountName, deployment.Namespace, upstreamLogicalName.String())
+		return fmt.Errorf("couldn't find a token upstream for the serviceaccount %s/%s in workspace %s", desiredServiceAccountName, namespace, upstreamLogicalName.String())
 	}
 
 	// Setting AutomountServiceAccountToken to false allow us to control the ServiceAccount
@@ -112,8 +135,8 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	// Set to empty the serviceAccountName on podTemplate as we are not syncing the serviceAccount down to the workload cluster.
 	templateSpec.ServiceAccountName = ""
 
-	kcpExternalHost := dm.upstreamURL.Hostname()
-	kcpExternalPort := dm.upstreamURL.Port()
+	kcpExternalHost := pm.upstreamURL.Hostname()
+	kcpExternalPort := pm.upstreamURL.Port()
 
 	overrideEnvs := []corev1.EnvVar{
 		{Name: "KUBERNETES_SERVICE_PORT", Value: kcpExternalPort},
@@ -121,14 +144,14 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		{Name: "KUBERNETES_SERVICE_HOST", Value: kcpExternalHost},
 	}
 
-	// This is the VolumeMount that we will append to all the containers of the deployment
+	// This is the VolumeMount that we will append to all the containers of the workload
 	serviceAccountMount := corev1.VolumeMount{
 		Name:      "kcp-api-access",
 		MountPath: "/var/run/secrets/kubernetes.io/serviceaccount",
 		ReadOnly:  true,
 	}
 
-	// This is the Volume that we will add to the Deployment in order to control
+	// This is the Volume that we will add to the workload in order to control
 	// the name of the ca.crt references (kcp-root-ca.crt vs kube-root-ca.crt)
 	// and the serviceaccount reference.
 	serviceAccountVolume := corev1.Volume{
@@ -173,11 +196,11 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	}
 
 	// Override Envs, resolve downwardAPI FieldRef and add the VolumeMount to all the containers
-	for i := range deployment.Spec.Template.Spec.Containers {
+	for i := range templateSpec.Containers {
 		for _, overrideEnv := range overrideEnvs {
 			templateSpec.Containers[i].Env = updateEnv(templateSpec.Containers[i].Env, overrideEnv)
 		}
-		templateSpec.Containers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.Containers[i].Env, deployment)
+		templateSpec.Containers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.Containers[i].Env, namespace)
 		templateSpec.Containers[i].VolumeMounts = updateVolumeMount(templateSpec.Containers[i].VolumeMounts, serviceAccountMount)
 	}
 
@@ -186,7 +209,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		for _, overrideEnv := range overrideEnvs {
 			templateSpec.InitContainers[i].Env = updateEnv(templateSpec.InitContainers[i].Env, overrideEnv)
 		}
-		templateSpec.InitContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.InitContainers[i].Env, deployment)
+		templateSpec.InitContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.InitContainers[i].Env, namespace)
 		templateSpec.InitContainers[i].VolumeMounts = updateVolumeMount(templateSpec.InitContainers[i].VolumeMounts, serviceAccountMount)
 	}
 
@@ -195,7 +218,7 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		for _, overrideEnv := range overrideEnvs {
 			templateSpec.EphemeralContainers[i].Env = updateEnv(templateSpec.EphemeralContainers[i].Env, overrideEnv)
 		}
-		templateSpec.EphemeralContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.EphemeralContainers[i].Env, deployment)
+		templateSpec.EphemeralContainers[i].Env = resolveDownwardAPIFieldRefEnv(templateSpec.EphemeralContainers[i].Env, namespace)
 		templateSpec.EphemeralContainers[i].VolumeMounts = updateVolumeMount(templateSpec.EphemeralContainers[i].VolumeMounts, serviceAccountMount)
 	}
 
@@ -212,13 +235,13 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 	}
 
 	// TODO: multiple worskpaces support. See https://github.com/kcp-dev/kcp/issues/1987
-	if dm.syncTargetLogicalClusterName == upstreamLogicalName {
+	if pm.syncTargetLogicalClusterName == upstreamLogicalName {
 		// Overrides DNS to point to the workspace DNS
-		deployment.Spec.Template.Spec.DNSPolicy = corev1.DNSNone
-		deployment.Spec.Template.Spec.DNSConfig = &corev1.PodDNSConfig{
-			Nameservers: []string{dm.dnsIP},
+		templateSpec.DNSPolicy = corev1.DNSNone
+		templateSpec.DNSConfig = &corev1.PodDNSConfig{
+			Nameservers: []string{pm.dnsIP},
 			Searches: []string{ // TODO(LV): from /etc/resolv.conf
-				obj.GetNamespace() + ".svc.cluster.local",
+				namespace + ".svc.cluster.local",
 				"svc.cluster.local",
 				"cluster.local",
 			},
@@ -231,25 +254,17 @@ func (dm *DeploymentMutator) Mutate(obj *unstructured.Unstructured) error {
 		}
 	}
 
-	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
-	if err != nil {
-		return err
-	}
-
-	// Set the changes back into the obj.
-	obj.SetUnstructuredContent(unstructured)
-
 	return nil
 }
 
-// resolveDownwardAPIFieldRefEnv replaces the downwardAPI FieldRef EnvVars with the value from the deployment, right now it only replaces the metadata.namespace
-func resolveDownwardAPIFieldRefEnv(envs []corev1.EnvVar, deployment appsv1.Deployment) []corev1.EnvVar {
+// resolveDownwardAPIFieldRefEnv replaces the downwardAPI FieldRef EnvVars with the value from the workload, right now it only replaces the metadata.namespace
+func resolveDownwardAPIFieldRefEnv(envs []corev1.EnvVar, namespace string) []corev1.EnvVar {
 	var result []corev1.EnvVar
 	for _, env := range envs {
 		if env.ValueFrom != nil && env.ValueFrom.FieldRef != nil && env.ValueFrom.FieldRef.FieldPath == "metadata.namespace" {
 			result = append(result, corev1.EnvVar{
 				Name:  env.Name,
-				Value: deployment.Namespace,
+				Value: namespace,
 			})
 		} else {
 			result = append(result, env)
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+type GetSyncTargetFunc func() (*workloadv1alpha1.SyncTarget, error)
+
+// StatefulSetMutator mutates the pod template of StatefulSets like the one of Deployments, and
+// maps the storage classes of their volume claim templates to the storage classes of the SyncTarget.
+type StatefulSetMutator struct {
+	podSpecMutator
+	getSyncTarget GetSyncTargetFunc
+}
+
+func (sm *StatefulSetMutator) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "statefulsets",
+	}
+}
+
+func NewStatefulSetMutator(upstreamURL *url.URL, secretLister ListSecretFunc, syncTargetLogicalClusterName logicalcluster.Name, dnsIP string, getSyncTarget GetSyncTargetFunc) *StatefulSetMutator {
+	return &StatefulSetMutator{
+		podSpecMutator: podSpecMutator{
+			upstreamURL:                  upstreamURL,
+			listSecrets:                  secretLister,
+			syncTargetLogicalClusterName: syncTargetLogicalClusterName,
+			dnsIP:                        dnsIP,
+		},
+		getSyncTarget: getSyncTarget,
+	}
+}
+
+// Mutate applies the mutator changes to the object.
+func (sm *StatefulSetMutator) Mutate(obj *unstructured.Unstructured) error {
+	var statefulSet appsv1.StatefulSet
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(
+		obj.UnstructuredContent(),
+		&statefulSet)
+	if err != nil {
+		return err
+	}
+
+	if err := sm.mutate(logicalcluster.From(obj), statefulSet.Namespace, &statefulSet.Spec.Template.Spec); err != nil {
+		return err
+	}
+
+	if len(statefulSet.Spec.VolumeClaimTemplates) > 0 {
+		syncTarget, err := sm.getSyncTarget()
+		if err != nil {
+			return fmt.Errorf("error getting SyncTarget to map storage classes: %w", err)
+		}
+
+		storageClasses := make(map[string]string, len(syncTarget.Spec.StorageClasses))
+		for _, m := range syncTarget.Spec.StorageClasses {
+			storageClasses[m.Upstream] = m.Downstream
+		}
+
+		for i := range statefulSet.Spec.VolumeClaimTemplates {
+			mapStorageClass(&statefulSet.Spec.VolumeClaimTemplates[i], storageClasses)
+		}
+	}
+
+	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&statefulSet)
+	if err != nil {
+		return err
+	}
+
+	// Set the changes back into the obj.
+	obj.SetUnstructuredContent(unstructured)
+
+	return nil
+}
+
+// mapStorageClass replaces the storage class requested by the claim, through the storageClassName field or the
+// deprecated beta annotation, with the mapped downstream storage class. Unmapped storage classes are kept.
+func mapStorageClass(claim *corev1.PersistentVolumeClaim, storageClasses map[string]string) {
+	if claim.Spec.StorageClassName != nil {
+		if downstream, found := storageClasses[*claim.Spec.StorageClassName]; found {
+			claim.Spec.StorageClassName = &downstream
+		}
+	}
+
+	if upstream, found := claim.Annotations[corev1.BetaStorageClassAnnotation]; found {
+		if downstream, found := storageClasses[upstream]; found {
+			claim.Annotations[corev1.BetaStorageClassAnnotation] = downstream
+		}
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster/v2"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilspointer "k8s.io/utils/pointer"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestStatefulSetMutate(t *testing.T) {
+	claim := func(storageClassName *string, annotations map[string]string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Annotations: annotations},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: storageClassName},
+		}
+	}
+	syncTarget := &workloadv1alpha1.SyncTarget{
+		Spec: workloadv1alpha1.SyncTargetSpec{
+			StorageClasses: []workloadv1alpha1.StorageClassMapping{
+				{Upstream: "fast", Downstream: "gp3"},
+				{Upstream: "standard", Downstream: "gp2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		claims           []corev1.PersistentVolumeClaim
+		getSyncTargetErr error
+		wantClaims       []corev1.PersistentVolumeClaim
+		wantErr          bool
+	}{
+		{
+			name: "no volume claim templates",
+		},
+		{
+			name:       "mapped storage class",
+			claims:     []corev1.PersistentVolumeClaim{claim(utilspointer.String("fast"), nil)},
+			wantClaims: []corev1.PersistentVolumeClaim{claim(utilspointer.String("gp3"), nil)},
+		},
+		{
+			name:       "unmapped storage class",
+			claims:     []corev1.PersistentVolumeClaim{claim(utilspointer.String("slow"), nil)},
+			wantClaims: []corev1.PersistentVolumeClaim{claim(utilspointer.String("slow"), nil)},
+		},
+		{
+			name:       "default storage class",
+			claims:     []corev1.PersistentVolumeClaim{claim(nil, nil)},
+			wantClaims: []corev1.PersistentVolumeClaim{claim(nil, nil)},
+		},
+		{
+			name:       "beta storage class annotation",
+			claims:     []corev1.PersistentVolumeClaim{claim(nil, map[string]string{corev1.BetaStorageClassAnnotation: "standard"})},
+			wantClaims: []corev1.PersistentVolumeClaim{claim(nil, map[string]string{corev1.BetaStorageClassAnnotation: "gp2"})},
+		},
+		{
+			name:             "SyncTarget not found",
+			claims:           []corev1.PersistentVolumeClaim{claim(utilspointer.String("fast"), nil)},
+			getSyncTargetErr: errors.New("not found"),
+			wantErr:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statefulSet := &appsv1.StatefulSet{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "StatefulSet",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-statefulset",
+					Namespace: "namespace",
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey: "root:default:testing",
+					},
+				},
+				Spec: appsv1.StatefulSetSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							ServiceAccountName: "default",
+							Containers:         []corev1.Container{{Name: "db", Image: "postgres"}},
+						},
+					},
+					VolumeClaimTemplates: tt.claims,
+				},
+			}
+
+			upstreamURL, err := url.Parse("https://4.5.6.7:12345")
+			require.NoError(t, err)
+
+			secret, err := toUnstructured(&corev1.Secret{
+				TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default-token-1234",
+					Namespace: "namespace",
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey:         "root:default:testing",
+						"kubernetes.io/service-account.name": "default",
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			sm := NewStatefulSetMutator(upstreamURL, func(clusterName logicalcluster.Name, namespace string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{secret}, nil
+			}, logicalcluster.New("root:default:testing"), "8.8.8.8", func() (*workloadv1alpha1.SyncTarget, error) {
+				return syncTarget, tt.getSyncTargetErr
+			})
+
+			obj, err := toUnstructured(statefulSet)
+			require.NoError(t, err)
+
+			err = sm.Mutate(obj)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			bs, err := json.Marshal(obj)
+			require.NoError(t, err)
+			var mutated appsv1.StatefulSet
+			require.NoError(t, json.Unmarshal(bs, &mutated))
+
+			podSpec := mutated.Spec.Template.Spec
+			require.Empty(t, podSpec.ServiceAccountName)
+			require.Equal(t, []corev1.Volume{kcpApiAccessVolume}, podSpec.Volumes)
+			require.Equal(t, []corev1.VolumeMount{kcpApiAccessVolumeMount}, podSpec.Containers[0].VolumeMounts)
+			require.Equal(t, corev1.DNSNone, podSpec.DNSPolicy)
+
+			require.Equal(t, tt.wantClaims, mutated.Spec.VolumeClaimTemplates)
+		})
+	}
+}
@@ -37,9 +37,12 @@ import (
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	workloadlisters "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
@@ -72,7 +75,7 @@ type Controller struct {
 
 func NewSpecSyncer(syncerLogger logr.Logger, syncTargetWorkspace logicalcluster.Name, syncTargetName, syncTargetKey string, upstreamURL *url.URL, advancedSchedulingEnabled bool,
 	upstreamClient dynamic.ClusterInterface, downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory, syncerInformers resourcesync.SyncerInformerFactory, syncTargetUID types.UID,
-	dnsIP string) (*Controller, error) {
+	syncTargetLister workloadlisters.SyncTargetLister, dnsIP string) (*Controller, error) {
 
 	c := Controller{
 		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
@@ -180,6 +183,9 @@ func NewSpecSyncer(syncerLogger logr.Logger, syncTargetWorkspace logicalcluster.
 
 	upstreamSecretIndexer := upstreamInformers.ForResource(schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}).Informer().GetIndexer()
 	deploymentMutator := specmutators.NewDeploymentMutator(upstreamURL, newSecretLister(upstreamSecretIndexer), syncTargetWorkspace, dnsIP)
+	statefulSetMutator := specmutators.NewStatefulSetMutator(upstreamURL, newSecretLister(upstreamSecretIndexer), syncTargetWorkspace, dnsIP, func() (*workloadv1alpha1.SyncTarget, error) {
+		return syncTargetLister.Get(clusters.ToClusterAwareKey(syncTargetWorkspace, syncTargetName))
+	})
 
 	if err := upstreamSecretIndexer.AddIndexers(cache.Indexers{
 		byWorkspaceAndNamespaceIndexName: indexByWorkspaceAndNamespace,
@@ -187,8 +193,9 @@ func NewSpecSyncer(syncerLogger logr.Logger, syncTargetWorkspace logicalcluster.
 		return nil, err
 	}
 	c.mutators = mutatorGvrMap{
-		deploymentMutator.GVR(): deploymentMutator.Mutate,
-		secretMutator.GVR():     secretMutator.Mutate,
+		deploymentMutator.GVR():  deploymentMutator.Mutate,
+		statefulSetMutator.GVR(): statefulSetMutator.Mutate,
+		secretMutator.GVR():      secretMutator.Mutate,
 	}
 
 	return &c, nil
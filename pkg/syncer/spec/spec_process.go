@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -357,6 +358,13 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 	delete(downstreamAnnotations, logicalcluster.AnnotationKey)
 	//TODO(jmprusi): To be removed when switching to the syncer Virtual Workspace transformations.
 	delete(downstreamAnnotations, workloadv1alpha1.InternalClusterStatusAnnotationPrefix+c.syncTargetKey)
+	// Record which upstream generation the downstream spec corresponds to, for the status syncer
+	if generation := upstreamObj.GetGeneration(); generation > 0 {
+		if downstreamAnnotations == nil {
+			downstreamAnnotations = make(map[string]string)
+		}
+		downstreamAnnotations[workloadv1alpha1.InternalUpstreamGenerationAnnotation] = strconv.FormatInt(generation, 10)
+	}
 	// If we're left with 0 annotations, nil out the map so it's not included in the patch
 	if len(downstreamAnnotations) == 0 {
 		downstreamAnnotations = nil
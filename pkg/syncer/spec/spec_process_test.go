@@ -47,6 +47,7 @@ import (
 	"k8s.io/klog/v2"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	workloadlisters "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/syncer/resourcesync"
 	"github.com/kcp-dev/kcp/third_party/keyfunctions"
 )
@@ -584,6 +585,67 @@ func TestSyncerProcess(t *testing.T) {
 				),
 			},
 		},
+		"SpecSyncer sync to downstream, upstream generation is recorded downstream": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"state.workload.kcp.dev/2gzO8uuQmIoZ2FE95zoOPKtrtGGXzzjAvtl6q5": "Sync",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResources: []runtime.Object{
+				secret("default-token-abc", "test", "root:org:ws",
+					map[string]string{"state.workload.kcp.dev/2gzO8uuQmIoZ2FE95zoOPKtrtGGXzzjAvtl6q5": "Sync"},
+					map[string]string{"kubernetes.io/service-account.name": "default"},
+					map[string][]byte{
+						"token":     []byte("token"),
+						"namespace": []byte("namespace"),
+					}),
+				changeDeployment(
+					deployment("theDeployment", "test", "root:org:ws", map[string]string{
+						"state.workload.kcp.dev/2gzO8uuQmIoZ2FE95zoOPKtrtGGXzzjAvtl6q5": "Sync",
+					}, nil, []string{"workload.kcp.dev/syncer-2gzO8uuQmIoZ2FE95zoOPKtrtGGXzzjAvtl6q5"}),
+					withGeneration(3),
+				),
+			},
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			syncTargetName:                      "us-west1",
+
+			expectActionsOnFrom: []clienttesting.Action{},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp-hcbsa8z6c2er", "",
+							map[string]string{
+								"internal.workload.kcp.dev/cluster": "2gzO8uuQmIoZ2FE95zoOPKtrtGGXzzjAvtl6q5",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"syncTarget":{"workspace":"root:org:ws","name":"us-west1","uid":"syncTargetUID"},"workspace":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				patchDeploymentAction(
+					"theDeployment",
+					"kcp-hcbsa8z6c2er",
+					types.ApplyPatchType,
+					toJson(t,
+						changeUnstructured(
+							toUnstructured(t, changeDeployment(
+								deployment("theDeployment", "kcp-hcbsa8z6c2er", "", map[string]string{
+									"internal.workload.kcp.dev/cluster": "2gzO8uuQmIoZ2FE95zoOPKtrtGGXzzjAvtl6q5",
+								}, map[string]string{
+									"internal.workload.kcp.dev/upstream-generation": "3",
+								}, nil),
+								withGeneration(3),
+							)),
+							setNestedField(map[string]interface{}{}, "status"),
+							setPodSpec("spec", "template", "spec"),
+						),
+					),
+				),
+			},
+		},
 		"SpecSyncer upstream resource has the state workload annotation removed, expect deletion downstream": {
 			upstreamLogicalCluster: "root:org:ws",
 			fromNamespace: namespace("test", "root:org:ws", map[string]string{
@@ -1004,7 +1066,7 @@ func TestSyncerProcess(t *testing.T) {
 
 			upstreamURL, err := url.Parse("https://kcp.dev:6443")
 			require.NoError(t, err)
-			controller, err := NewSpecSyncer(logger, kcpLogicalCluster, tc.syncTargetName, syncTargetKey, upstreamURL, tc.advancedSchedulingEnabled, fromClusterClient, toClient, fromInformers, toInformers, fakeInformers, syncTargetUID, "8.8.8.8")
+			controller, err := NewSpecSyncer(logger, kcpLogicalCluster, tc.syncTargetName, syncTargetKey, upstreamURL, tc.advancedSchedulingEnabled, fromClusterClient, toClient, fromInformers, toInformers, fakeInformers, syncTargetUID, workloadlisters.NewSyncTargetLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})), "8.8.8.8")
 			require.NoError(t, err)
 
 			fromInformers.Start(ctx.Done())
@@ -1128,6 +1190,12 @@ func changeDeployment(in *appsv1.Deployment, changes ...deploymentChange) *appsv
 	return in
 }
 
+func withGeneration(generation int64) deploymentChange {
+	return func(d *appsv1.Deployment) {
+		d.Generation = generation
+	}
+}
+
 func toJson(t require.TestingT, object runtime.Object) []byte {
 	result, err := json.Marshal(object)
 	require.NoError(t, err)
@@ -52,6 +52,7 @@ type Controller struct {
 	downstreamNamespaceLister cache.GenericLister
 
 	syncerInformers           resourcesync.SyncerInformerFactory
+	statusMutators            statusMutatorGvrMap
 	syncTargetName            string
 	syncTargetWorkspace       logicalcluster.Name
 	syncTargetUID             types.UID
@@ -69,7 +70,10 @@ func NewStatusSyncer(syncerLogger logr.Logger, syncTargetWorkspace logicalcluste
 		downstreamClient:          downstreamClient,
 		downstreamNamespaceLister: downstreamInformers.ForResource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).Lister(),
 
-		syncerInformers:           syncerInformers,
+		syncerInformers: syncerInformers,
+		statusMutators: statusMutatorGvrMap{
+			{Group: "apps", Version: "v1", Resource: "statefulsets"}: translateObservedGeneration,
+		},
 		syncTargetName:            syncTargetName,
 		syncTargetWorkspace:       syncTargetWorkspace,
 		syncTargetUID:             syncTargetUID,
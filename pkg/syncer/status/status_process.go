@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster/v2"
@@ -39,6 +40,10 @@ import (
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
+// statusMutatorGvrMap holds, per resource, a function translating the status of a downstream object
+// before it is written to the upstream object.
+type statusMutatorGvrMap map[schema.GroupVersionResource]func(upstream, downstream *unstructured.Unstructured, status map[string]interface{}) error
+
 func deepEqualFinalizersAndStatus(oldUnstrob, newUnstrob *unstructured.Unstructured) bool {
 	newFinalizers := newUnstrob.GetFinalizers()
 	oldFinalizers := oldUnstrob.GetFinalizers()
@@ -149,6 +154,16 @@ func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.Grou
 		return nil
 	}
 
+	if mutator, ok := c.statusMutators[gvr]; ok {
+		status, ok := downstreamStatus.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("status of downstream resource is expected to be an object, but is %T", downstreamStatus)
+		}
+		if err := mutator(existing, downstreamObj, status); err != nil {
+			return err
+		}
+	}
+
 	newUpstream := existing.DeepCopy()
 
 	if c.advancedSchedulingEnabled {
@@ -192,3 +207,35 @@ func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.Grou
 	logger.Info("Updated status of upstream resource")
 	return nil
 }
+
+// translateObservedGeneration replaces the observedGeneration of a downstream status, which refers to the
+// generation of the downstream object, with the generation of the upstream object. Once the downstream object
+// has observed its latest generation, the upstream generation recorded by the spec syncer when syncing that
+// spec is observed. Until then, the observedGeneration already reported upstream is kept. This keeps e.g.
+// `kubectl rollout status` working against kcp.
+func translateObservedGeneration(upstream, downstream *unstructured.Unstructured, status map[string]interface{}) error {
+	observedGeneration, found, err := unstructured.NestedInt64(status, "observedGeneration")
+	if err != nil || !found {
+		return err
+	}
+
+	if value, found := downstream.GetAnnotations()[workloadv1alpha1.InternalUpstreamGenerationAnnotation]; found && observedGeneration >= downstream.GetGeneration() {
+		syncedGeneration, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s annotation %q: %w", workloadv1alpha1.InternalUpstreamGenerationAnnotation, value, err)
+		}
+		status["observedGeneration"] = syncedGeneration
+		return nil
+	}
+
+	previous, found, err := unstructured.NestedInt64(upstream.UnstructuredContent(), "status", "observedGeneration")
+	if err != nil {
+		return err
+	}
+	if found {
+		status["observedGeneration"] = previous
+	} else {
+		delete(status, "observedGeneration")
+	}
+	return nil
+}
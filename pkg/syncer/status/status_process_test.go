@@ -303,6 +303,88 @@ func (mdc *mockedDynamicCluster) Cluster(name logicalcluster.Name) dynamic.Inter
 	return mdc.client
 }
 
+func TestTranslateObservedGeneration(t *testing.T) {
+	upstreamWithObservedGeneration := func(observedGeneration int64) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetGeneration(9)
+		if observedGeneration > 0 {
+			require.NoError(t, unstructured.SetNestedField(obj.Object, observedGeneration, "status", "observedGeneration"))
+		}
+		return obj
+	}
+	downstreamWithSyncedGeneration := func(generation int64, syncedGeneration string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetGeneration(generation)
+		if syncedGeneration != "" {
+			obj.SetAnnotations(map[string]string{workloadv1alpha1.InternalUpstreamGenerationAnnotation: syncedGeneration})
+		}
+		return obj
+	}
+
+	tests := []struct {
+		name                   string
+		status                 map[string]interface{}
+		upstream               *unstructured.Unstructured
+		downstream             *unstructured.Unstructured
+		wantObservedGeneration interface{}
+		wantErr                bool
+	}{
+		{
+			name:                   "no observed generation",
+			status:                 map[string]interface{}{"readyReplicas": int64(1)},
+			upstream:               upstreamWithObservedGeneration(3),
+			downstream:             downstreamWithSyncedGeneration(2, "5"),
+			wantObservedGeneration: nil,
+		},
+		{
+			name:                   "latest downstream generation observed",
+			status:                 map[string]interface{}{"observedGeneration": int64(2)},
+			upstream:               upstreamWithObservedGeneration(3),
+			downstream:             downstreamWithSyncedGeneration(2, "5"),
+			wantObservedGeneration: int64(5),
+		},
+		{
+			name:                   "latest downstream generation not observed yet",
+			status:                 map[string]interface{}{"observedGeneration": int64(1)},
+			upstream:               upstreamWithObservedGeneration(3),
+			downstream:             downstreamWithSyncedGeneration(2, "5"),
+			wantObservedGeneration: int64(3),
+		},
+		{
+			name:                   "nothing observed upstream yet",
+			status:                 map[string]interface{}{"observedGeneration": int64(1)},
+			upstream:               upstreamWithObservedGeneration(0),
+			downstream:             downstreamWithSyncedGeneration(2, "5"),
+			wantObservedGeneration: nil,
+		},
+		{
+			name:                   "synced upstream generation unknown",
+			status:                 map[string]interface{}{"observedGeneration": int64(2)},
+			upstream:               upstreamWithObservedGeneration(3),
+			downstream:             downstreamWithSyncedGeneration(2, ""),
+			wantObservedGeneration: int64(3),
+		},
+		{
+			name:       "invalid synced upstream generation",
+			status:     map[string]interface{}{"observedGeneration": int64(2)},
+			upstream:   upstreamWithObservedGeneration(3),
+			downstream: downstreamWithSyncedGeneration(2, "five"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateObservedGeneration(tt.upstream, tt.downstream, tt.status)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantObservedGeneration, tt.status["observedGeneration"])
+		})
+	}
+}
+
 func TestSyncerProcess(t *testing.T) {
 	tests := map[string]struct {
 		fromNamespace *corev1.Namespace
@@ -23,9 +23,15 @@ import (
 
 	"github.com/kcp-dev/logicalcluster/v2"
 
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clusters"
 
@@ -41,17 +47,20 @@ type maximalPermissionAuthorizer struct {
 	getAPIExport            func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error)
 	newDeepSARAuthorizer    func(clusterName logicalcluster.Name) (authorizer.Authorizer, error)
 	getAPIExportsByIdentity func(identityHash string) ([]*apisv1alpha1.APIExport, error)
+	getNamespace            func(clusterName logicalcluster.Name, name string) (*corev1.Namespace, error)
 }
 
 // NewMaximalPermissionAuthorizer creates an authorizer that checks the maximal permission policy
 // for the requested resource if the resource is a claimed resource in the requested API export.
 // The check is omitted if the requested resource itself is not associated with an API export.
+// Requests for claimed resources with verbs or namespaces the claim is not restricted to are denied.
 //
 // If the request is a cluster request the authorizer skips authorization if the request is not for a bound resource.
 // If the request is a wildcard request this check is skipped because no unique API binding can be determined.
-func NewMaximalPermissionAuthorizer(deepSARClient kubernetes.ClusterInterface, apiExportInformer apisinformers.APIExportInformer, apiBindingInformer apisinformers.APIBindingInformer) authorizer.Authorizer {
+func NewMaximalPermissionAuthorizer(deepSARClient kubernetes.ClusterInterface, apiExportInformer apisinformers.APIExportInformer, apiBindingInformer apisinformers.APIBindingInformer, namespaceInformer coreinformers.NamespaceInformer) authorizer.Authorizer {
 	apiExportLister := apiExportInformer.Lister()
 	apiExportIndexer := apiExportInformer.Informer().GetIndexer()
+	namespaceLister := namespaceInformer.Lister()
 
 	auth := &maximalPermissionAuthorizer{
 		getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
@@ -63,6 +72,9 @@ func NewMaximalPermissionAuthorizer(deepSARClient kubernetes.ClusterInterface, a
 		newDeepSARAuthorizer: func(clusterName logicalcluster.Name) (authorizer.Authorizer, error) {
 			return delegated.NewDelegatedAuthorizer(clusterName, deepSARClient)
 		},
+		getNamespace: func(clusterName logicalcluster.Name, name string) (*corev1.Namespace, error) {
+			return namespaceLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
 	}
 
 	return authorization.NewAnonymizer("virtual apiexport maximum permission policy authorizer",
@@ -87,12 +99,25 @@ func (a *maximalPermissionAuthorizer) Authorize(ctx context.Context, attr author
 		return authorizer.DecisionNoOpinion, "", err
 	}
 
-	claimedIdentityHash, found := getClaimedIdentity(claimingAPIExport, attr)
+	claim, found := getPermissionClaim(claimingAPIExport, attr)
 	if !found {
 		// it's a resource in the claiming API export, hence unclaimed
 		return authorizer.DecisionAllow, fmt.Sprintf("unclaimed resource in API export: %q, workspace :%q",
 			claimingAPIExport.Name, logicalcluster.From(claimingAPIExport)), nil
 	}
+	if !claim.AllowsVerb(attr.GetVerb()) {
+		return authorizer.DecisionDeny, fmt.Sprintf("permission claim in API export: %q, workspace :%q does not allow verb %q",
+			claimingAPIExport.Name, logicalcluster.From(claimingAPIExport), attr.GetVerb()), nil
+	}
+	allowed, err := a.claimAllowsNamespace(ctx, claim, attr)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	if !allowed {
+		return authorizer.DecisionDeny, fmt.Sprintf("permission claim in API export: %q, workspace :%q is restricted to other namespaces",
+			claimingAPIExport.Name, logicalcluster.From(claimingAPIExport)), nil
+	}
+	claimedIdentityHash := claim.IdentityHash
 	if claimedIdentityHash == "" {
 		// it's a native k8s resource (secret, configmap, ...), or a system kcp CRD resource (apis.kcp.dev)
 		// For neither case a maximum permission policy can exist.
@@ -144,14 +169,65 @@ func (a *maximalPermissionAuthorizer) Authorize(ctx context.Context, attr author
 	return authorizer.DecisionAllow, "all claimed API exports granted access", nil
 }
 
-func getClaimedIdentity(apiExport *apisv1alpha1.APIExport, attr authorizer.Attributes) (string, bool) {
+// getPermissionClaim returns the claim of the API export for the requested resource. There is at most one,
+// because permission claims are keyed by group and resource.
+func getPermissionClaim(apiExport *apisv1alpha1.APIExport, attr authorizer.Attributes) (*apisv1alpha1.PermissionClaim, bool) {
 	for i := range apiExport.Spec.PermissionClaims {
 		if apiExport.Spec.PermissionClaims[i].Resource == attr.GetResource() &&
 			apiExport.Spec.PermissionClaims[i].Group == attr.GetAPIGroup() {
-			return apiExport.Spec.PermissionClaims[i].IdentityHash, true
+			return &apiExport.Spec.PermissionClaims[i], true
 		}
 	}
-	return "", false
+	return nil, false
+}
+
+// claimAllowsNamespace returns true if the claim is not restricted to namespaces, or if the request
+// targets a claimed namespace. For the namespaces resource, the requested namespace itself is checked.
+//
+// A restricted claim never gives access to other cluster-scoped objects. Only list and watch requests
+// across namespaces, or across workspaces with a namespace selector, are allowed, because these are
+// filtered by the claim labels, which are only set on objects in claimed namespaces. Namespaces cannot
+// be created through a restricted claim because their name is not known at authorization time.
+func (a *maximalPermissionAuthorizer) claimAllowsNamespace(ctx context.Context, claim *apisv1alpha1.PermissionClaim, attr authorizer.Attributes) (bool, error) {
+	if !claim.IsNamespaceRestricted() {
+		return true, nil
+	}
+
+	filteredByLabels := attr.GetVerb() == "list" || attr.GetVerb() == "watch"
+	namespace := attr.GetNamespace()
+	if attr.GetAPIGroup() == "" && attr.GetResource() == "namespaces" {
+		namespace = attr.GetName()
+	}
+	if namespace == "" {
+		return filteredByLabels, nil
+	}
+
+	if sets.NewString(claim.Namespaces...).Has(namespace) {
+		return true, nil
+	}
+	if claim.NamespaceSelector == nil {
+		return false, nil
+	}
+
+	cluster := genericapirequest.ClusterFrom(ctx)
+	if cluster == nil {
+		return false, fmt.Errorf("no cluster found in request context")
+	}
+	if cluster.Wildcard {
+		return filteredByLabels, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(claim.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	ns, err := a.getNamespace(cluster.Name, namespace)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
 }
 
 func prefixAttributes(attr authorizer.Attributes) *authorizer.AttributesRecord {
@@ -23,19 +23,45 @@ import (
 	logicalcluster "github.com/kcp-dev/logicalcluster/v2"
 	"github.com/stretchr/testify/require"
 
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
 )
 
 func TestMaximalPermissionPolicyAuthorizer(t *testing.T) {
+	claimingAPIExport := func(claims ...apisv1alpha1.PermissionClaim) func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+		return func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+			return &apisv1alpha1.APIExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "fooExport",
+					Annotations: map[string]string{
+						logicalcluster.AnnotationKey: "someWorkspace",
+					},
+				},
+				Spec: apisv1alpha1.APIExportSpec{PermissionClaims: claims},
+			}, nil
+		}
+	}
+	selectedSecrets := apisv1alpha1.PermissionClaim{
+		GroupResource:     apisv1alpha1.GroupResource{Resource: "secrets"},
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+	}
+	namespaces := map[string]*corev1.Namespace{
+		"selected": {ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"team": "a"}}},
+		"other":    {ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"team": "b"}}},
+	}
+
 	for _, tc := range []struct {
 		name                    string
 		attr                    authorizer.Attributes
 		apidomainKey            string
+		wildcard                bool
 		getAPIExport            func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error)
 		getAPIExportsByIdentity func(identityHash string) ([]*apisv1alpha1.APIExport, error)
 		newDeepSARAuthorizer    func(clusterName logicalcluster.Name) (authorizer.Authorizer, error)
@@ -107,6 +133,327 @@ func TestMaximalPermissionPolicyAuthorizer(t *testing.T) {
 			expectedDecision: authorizer.DecisionAllow,
 			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
 		},
+		{
+			name: "claim allowing the requested verb",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "list",
+				APIGroup: "",
+				Resource: "configmaps",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+				return &apisv1alpha1.APIExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "fooExport",
+						Annotations: map[string]string{
+							logicalcluster.AnnotationKey: "someWorkspace",
+						},
+					},
+					Spec: apisv1alpha1.APIExportSpec{
+						PermissionClaims: []apisv1alpha1.PermissionClaim{
+							{
+								GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"},
+								Verbs:         []string{"get", "list", "watch"},
+							},
+						},
+					},
+				}, nil
+			},
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "claim not allowing the requested verb",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "delete",
+				APIGroup: "",
+				Resource: "configmaps",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+				return &apisv1alpha1.APIExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "fooExport",
+						Annotations: map[string]string{
+							logicalcluster.AnnotationKey: "someWorkspace",
+						},
+					},
+					Spec: apisv1alpha1.APIExportSpec{
+						PermissionClaims: []apisv1alpha1.PermissionClaim{
+							{
+								GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"},
+								Verbs:         []string{"get", "list", "watch"},
+							},
+						},
+					},
+				}, nil
+			},
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" does not allow verb "delete"`,
+		},
+		{
+			name: "claim restricted to the requested namespace",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Namespace: "allowed",
+				APIGroup:  "",
+				Resource:  "secrets",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+				return &apisv1alpha1.APIExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "fooExport",
+						Annotations: map[string]string{
+							logicalcluster.AnnotationKey: "someWorkspace",
+						},
+					},
+					Spec: apisv1alpha1.APIExportSpec{
+						PermissionClaims: []apisv1alpha1.PermissionClaim{
+							{
+								GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"},
+								Namespaces:    []string{"allowed"},
+							},
+						},
+					},
+				}, nil
+			},
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "claim restricted to other namespaces",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Namespace: "other",
+				APIGroup:  "",
+				Resource:  "secrets",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: func(clusterName, apiExportName string) (*apisv1alpha1.APIExport, error) {
+				return &apisv1alpha1.APIExport{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "fooExport",
+						Annotations: map[string]string{
+							logicalcluster.AnnotationKey: "someWorkspace",
+						},
+					},
+					Spec: apisv1alpha1.APIExportSpec{
+						PermissionClaims: []apisv1alpha1.PermissionClaim{
+							{
+								GroupResource: apisv1alpha1.GroupResource{Resource: "secrets"},
+								Namespaces:    []string{"allowed"},
+							},
+						},
+					},
+				}, nil
+			},
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
+		{
+			name: "namespace selector claim allowing writes in a selected namespace",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Verb:      "update",
+				Namespace: "selected",
+				Resource:  "secrets",
+				Name:      "foo",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(selectedSecrets),
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "namespace selector claim denying writes in an unselected namespace",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Verb:      "create",
+				Namespace: "other",
+				Resource:  "secrets",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(selectedSecrets),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
+		{
+			name: "namespace selector claim denying writes in an unknown namespace",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Verb:      "delete",
+				Namespace: "unknown",
+				Resource:  "secrets",
+				Name:      "foo",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(selectedSecrets),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
+		{
+			name: "namespace selector claim leaving wildcard lists to the claim labels",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Verb:      "list",
+				Namespace: "other",
+				Resource:  "secrets",
+			},
+			apidomainKey: "foo/bar",
+			wildcard:     true,
+			getAPIExport: claimingAPIExport(selectedSecrets),
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "restricted claim leaving lists across namespaces to the claim labels",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "watch",
+				Resource: "secrets",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(selectedSecrets),
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "restricted claim denying creation of cluster-scoped objects",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "create",
+				APIGroup: "rbac.authorization.k8s.io",
+				Resource: "clusterroles",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource: apisv1alpha1.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+				Namespaces:    []string{"allowed"},
+			}),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
+		{
+			name: "restricted claim denying deletion of cluster-scoped objects",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "delete",
+				APIGroup: "rbac.authorization.k8s.io",
+				Resource: "clusterroles",
+				Name:     "foo",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource:     apisv1alpha1.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+				NamespaceSelector: &metav1.LabelSelector{},
+			}),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
+		{
+			name: "namespaces claim allowing deletion of a claimed namespace",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "delete",
+				Resource: "namespaces",
+				Name:     "a",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource: apisv1alpha1.GroupResource{Resource: "namespaces"},
+				Namespaces:    []string{"a"},
+			}),
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "namespaces claim denying deletion of another namespace",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "delete",
+				Resource: "namespaces",
+				Name:     "b",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource: apisv1alpha1.GroupResource{Resource: "namespaces"},
+				Namespaces:    []string{"a"},
+			}),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
+		{
+			name: "verb and namespace restricted claim allowing the requested verb in the namespace",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Verb:      "get",
+				Namespace: "x",
+				Resource:  "configmaps",
+				Name:      "foo",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"},
+				Namespaces:    []string{"x"},
+				Verbs:         []string{"get", "list", "watch"},
+			}),
+
+			expectedDecision: authorizer.DecisionAllow,
+			expectedReason:   `unclaimable resource, identity hash not set in claiming API export: "fooExport", workspace :"someWorkspace"`,
+		},
+		{
+			name: "verb and namespace restricted claim denying another verb in the namespace",
+			attr: &authorizer.AttributesRecord{
+				User:      &user.DefaultInfo{},
+				Verb:      "update",
+				Namespace: "x",
+				Resource:  "configmaps",
+				Name:      "foo",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource: apisv1alpha1.GroupResource{Resource: "configmaps"},
+				Namespaces:    []string{"x"},
+				Verbs:         []string{"get", "list", "watch"},
+			}),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" does not allow verb "update"`,
+		},
+		{
+			name: "verb restricted claim denying cluster-scoped writes in a namespace",
+			attr: &authorizer.AttributesRecord{
+				User:     &user.DefaultInfo{},
+				Verb:     "delete",
+				Resource: "namespaces",
+				Name:     "b",
+			},
+			apidomainKey: "foo/bar",
+			getAPIExport: claimingAPIExport(apisv1alpha1.PermissionClaim{
+				GroupResource:     apisv1alpha1.GroupResource{Resource: "namespaces"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+				Verbs:             []string{"delete"},
+			}),
+
+			expectedDecision: authorizer.DecisionDeny,
+			expectedReason:   `permission claim in API export: "fooExport", workspace :"someWorkspace" is restricted to other namespaces`,
+		},
 		{
 			name: "claimed identity without api export",
 			attr: &authorizer.AttributesRecord{
@@ -315,10 +662,22 @@ func TestMaximalPermissionPolicyAuthorizer(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := dynamiccontext.WithAPIDomainKey(context.Background(), dynamiccontext.APIDomainKey(tc.apidomainKey))
+			if tc.wildcard {
+				ctx = genericapirequest.WithCluster(ctx, genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true})
+			} else {
+				ctx = genericapirequest.WithCluster(ctx, genericapirequest.Cluster{Name: logicalcluster.New("root:consumer")})
+			}
 			auth := &maximalPermissionAuthorizer{
 				getAPIExport:            tc.getAPIExport,
 				getAPIExportsByIdentity: tc.getAPIExportsByIdentity,
 				newDeepSARAuthorizer:    tc.newDeepSARAuthorizer,
+				getNamespace: func(clusterName logicalcluster.Name, name string) (*corev1.Namespace, error) {
+					require.Equal(t, "root:consumer", clusterName.String())
+					if ns, found := namespaces[name]; found {
+						return ns, nil
+					}
+					return nil, kerrors.NewNotFound(corev1.Resource("namespaces"), name)
+				},
 			}
 			dec, reason, err := auth.Authorize(ctx, tc.attr)
 			errString := ""
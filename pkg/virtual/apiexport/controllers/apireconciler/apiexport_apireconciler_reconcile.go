@@ -180,17 +180,6 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 				Resource: apiResourceSchema.Spec.Names.Plural,
 			}
 
-			oldDef, found := oldSet[gvr]
-			if found {
-				oldDef := oldDef.(apiResourceSchemaApiDefinition)
-				if oldDef.UID == apiResourceSchema.UID && oldDef.IdentityHash == apiExport.Status.IdentityHash {
-					// this is the same schema and identity as before. no need to update.
-					newSet[gvr] = oldDef
-					preservedGVR = append(preservedGVR, gvrString(gvr))
-					continue
-				}
-			}
-
 			var labelReqs labels.Requirements
 			if c := claims[gvr.GroupResource()]; c != nil {
 				key, label, err := permissionclaims.ToLabelKeyAndValue(clusterName, apiExport.Name, *c)
@@ -208,6 +197,18 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 				}
 				labelReqs = labels.Requirements{*req}
 			}
+			claimSelector := labels.NewSelector().Add(labelReqs...).String()
+
+			oldDef, found := oldSet[gvr]
+			if found {
+				oldDef := oldDef.(apiResourceSchemaApiDefinition)
+				if oldDef.UID == apiResourceSchema.UID && oldDef.IdentityHash == apiExport.Status.IdentityHash && oldDef.ClaimSelector == claimSelector {
+					// this is the same schema, identity and claim as before. no need to update.
+					newSet[gvr] = oldDef
+					preservedGVR = append(preservedGVR, gvrString(gvr))
+					continue
+				}
+			}
 
 			logger.Info("creating API definition", "gvr", gvr, "labels", labelReqs)
 			apiDefinition, err := c.createAPIDefinition(apiResourceSchema, version.Name, identities[gvr.GroupResource()], labelReqs)
@@ -221,6 +222,7 @@ func (c *APIReconciler) reconcile(ctx context.Context, apiExport *apisv1alpha1.A
 				APIDefinition: apiDefinition,
 				UID:           apiResourceSchema.UID,
 				IdentityHash:  apiExport.Status.IdentityHash,
+				ClaimSelector: claimSelector,
 			}
 			newGVRs = append(newGVRs, gvrString(gvr))
 		}
@@ -249,6 +251,9 @@ type apiResourceSchemaApiDefinition struct {
 
 	UID          types.UID
 	IdentityHash string
+	// ClaimSelector is the label selector of a claimed resource. It changes with the claim,
+	// e.g. when the claim is restricted to namespaces.
+	ClaimSelector string
 }
 
 func gvrString(gvr schema.GroupVersionResource) string {
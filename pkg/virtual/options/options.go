@@ -83,7 +83,7 @@ func (o *Options) NewVirtualWorkspaces(
 		return nil, err
 	}
 
-	apiexports, err := o.APIExport.NewVirtualWorkspaces(rootPathPrefix, config, wildcardKcpInformers)
+	apiexports, err := o.APIExport.NewVirtualWorkspaces(rootPathPrefix, config, wildcardKubeInformers, wildcardKcpInformers)
 	if err != nil {
 		return nil, err
 	}